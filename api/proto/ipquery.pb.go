@@ -24,7 +24,8 @@ const (
 // 查询IP请求
 type QueryIPRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Ip            string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"` // IP地址
+	Ip            string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`     // IP地址
+	Lang          string                 `protobuf:"bytes,2,opt,name=lang,proto3" json:"lang,omitempty"` // 响应语言: zh-CN(默认), en
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -66,6 +67,13 @@ func (x *QueryIPRequest) GetIp() string {
 	return ""
 }
 
+func (x *QueryIPRequest) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
 // 查询IP响应
 type QueryIPResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -122,7 +130,8 @@ func (x *QueryIPResponse) GetTimestamp() int64 {
 // 批量查询IP请求
 type BatchQueryIPRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Ips           []string               `protobuf:"bytes,1,rep,name=ips,proto3" json:"ips,omitempty"` // IP地址列表
+	Ips           []string               `protobuf:"bytes,1,rep,name=ips,proto3" json:"ips,omitempty"`   // IP地址列表
+	Lang          string                 `protobuf:"bytes,2,opt,name=lang,proto3" json:"lang,omitempty"` // 响应语言: zh-CN(默认), en
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -164,6 +173,13 @@ func (x *BatchQueryIPRequest) GetIps() []string {
 	return nil
 }
 
+func (x *BatchQueryIPRequest) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
 // 批量查询IP响应
 type BatchQueryIPResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -217,27 +233,30 @@ func (x *BatchQueryIPResponse) GetTimestamp() int64 {
 	return 0
 }
 
-// 获取服务状态请求
-type GetServiceStatusRequest struct {
+// 查询网段请求
+type QueryCIDRRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prefix        string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"` // 网段，如 114.114.0.0/16
+	Lang          string                 `protobuf:"bytes,2,opt,name=lang,proto3" json:"lang,omitempty"`     // 响应语言: zh-CN(默认), en
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`  // 返回的子范围数量上限，0表示默认值
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetServiceStatusRequest) Reset() {
-	*x = GetServiceStatusRequest{}
+func (x *QueryCIDRRequest) Reset() {
+	*x = QueryCIDRRequest{}
 	mi := &file_api_proto_ipquery_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetServiceStatusRequest) String() string {
+func (x *QueryCIDRRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetServiceStatusRequest) ProtoMessage() {}
+func (*QueryCIDRRequest) ProtoMessage() {}
 
-func (x *GetServiceStatusRequest) ProtoReflect() protoreflect.Message {
+func (x *QueryCIDRRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_proto_ipquery_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -249,36 +268,60 @@ func (x *GetServiceStatusRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetServiceStatusRequest.ProtoReflect.Descriptor instead.
-func (*GetServiceStatusRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use QueryCIDRRequest.ProtoReflect.Descriptor instead.
+func (*QueryCIDRRequest) Descriptor() ([]byte, []int) {
 	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{4}
 }
 
-// 获取服务状态响应
-type GetServiceStatusResponse struct {
+func (x *QueryCIDRRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *QueryCIDRRequest) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+func (x *QueryCIDRRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// 查询网段响应
+type QueryCIDRResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`                            // 服务状态
-	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`                          // 服务版本
-	Uptime        int64                  `protobuf:"varint,3,opt,name=uptime,proto3" json:"uptime,omitempty"`                           // 运行时间(秒)
-	QueryCount    int64                  `protobuf:"varint,4,opt,name=query_count,json=queryCount,proto3" json:"query_count,omitempty"` // 查询次数
+	Prefix        string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`                               // 规范化后的网段
+	Ranges        []*IPInfo              `protobuf:"bytes,2,rep,name=ranges,proto3" json:"ranges,omitempty"`                               // 网段内的子范围
+	TotalRanges   int32                  `protobuf:"varint,3,opt,name=total_ranges,json=totalRanges,proto3" json:"total_ranges,omitempty"` // 子范围总数
+	Truncated     bool                   `protobuf:"varint,4,opt,name=truncated,proto3" json:"truncated,omitempty"`                        // 子范围是否被截断
+	Countries     []*CIDRSummaryItem     `protobuf:"bytes,5,rep,name=countries,proto3" json:"countries,omitempty"`                         // 按国家汇总
+	Isps          []*CIDRSummaryItem     `protobuf:"bytes,6,rep,name=isps,proto3" json:"isps,omitempty"`                                   // 按ISP汇总
+	Timestamp     int64                  `protobuf:"varint,7,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                        // 查询时间戳
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetServiceStatusResponse) Reset() {
-	*x = GetServiceStatusResponse{}
+func (x *QueryCIDRResponse) Reset() {
+	*x = QueryCIDRResponse{}
 	mi := &file_api_proto_ipquery_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetServiceStatusResponse) String() string {
+func (x *QueryCIDRResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetServiceStatusResponse) ProtoMessage() {}
+func (*QueryCIDRResponse) ProtoMessage() {}
 
-func (x *GetServiceStatusResponse) ProtoReflect() protoreflect.Message {
+func (x *QueryCIDRResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_api_proto_ipquery_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -290,73 +333,85 @@ func (x *GetServiceStatusResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetServiceStatusResponse.ProtoReflect.Descriptor instead.
-func (*GetServiceStatusResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use QueryCIDRResponse.ProtoReflect.Descriptor instead.
+func (*QueryCIDRResponse) Descriptor() ([]byte, []int) {
 	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *GetServiceStatusResponse) GetStatus() string {
+func (x *QueryCIDRResponse) GetPrefix() string {
 	if x != nil {
-		return x.Status
+		return x.Prefix
 	}
 	return ""
 }
 
-func (x *GetServiceStatusResponse) GetVersion() string {
+func (x *QueryCIDRResponse) GetRanges() []*IPInfo {
 	if x != nil {
-		return x.Version
+		return x.Ranges
 	}
-	return ""
+	return nil
 }
 
-func (x *GetServiceStatusResponse) GetUptime() int64 {
+func (x *QueryCIDRResponse) GetTotalRanges() int32 {
 	if x != nil {
-		return x.Uptime
+		return x.TotalRanges
 	}
 	return 0
 }
 
-func (x *GetServiceStatusResponse) GetQueryCount() int64 {
+func (x *QueryCIDRResponse) GetTruncated() bool {
 	if x != nil {
-		return x.QueryCount
+		return x.Truncated
+	}
+	return false
+}
+
+func (x *QueryCIDRResponse) GetCountries() []*CIDRSummaryItem {
+	if x != nil {
+		return x.Countries
+	}
+	return nil
+}
+
+func (x *QueryCIDRResponse) GetIsps() []*CIDRSummaryItem {
+	if x != nil {
+		return x.Isps
+	}
+	return nil
+}
+
+func (x *QueryCIDRResponse) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
 	}
 	return 0
 }
 
-// IP信息
-type IPInfo struct {
+// 网段汇总项
+type CIDRSummaryItem struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Ip            string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`                                          // IP地址
-	Country       string                 `protobuf:"bytes,2,opt,name=country,proto3" json:"country,omitempty"`                                // 国家
-	CountryCode   string                 `protobuf:"bytes,3,opt,name=country_code,json=countryCode,proto3" json:"country_code,omitempty"`     // 国家代码
-	Region        string                 `protobuf:"bytes,4,opt,name=region,proto3" json:"region,omitempty"`                                  // 省份/州
-	City          string                 `protobuf:"bytes,5,opt,name=city,proto3" json:"city,omitempty"`                                      // 城市
-	District      string                 `protobuf:"bytes,6,opt,name=district,proto3" json:"district,omitempty"`                              // 区县
-	Isp           string                 `protobuf:"bytes,7,opt,name=isp,proto3" json:"isp,omitempty"`                                        // ISP
-	Latitude      float32                `protobuf:"fixed32,8,opt,name=latitude,proto3" json:"latitude,omitempty"`                            // 纬度
-	Longitude     float32                `protobuf:"fixed32,9,opt,name=longitude,proto3" json:"longitude,omitempty"`                          // 经度
-	Timezone      string                 `protobuf:"bytes,10,opt,name=timezone,proto3" json:"timezone,omitempty"`                             // 时区
-	PostalCode    string                 `protobuf:"bytes,11,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`       // 邮政编码
-	IsValid       bool                   `protobuf:"varint,12,opt,name=is_valid,json=isValid,proto3" json:"is_valid,omitempty"`               // 是否有效IP
-	ErrorMessage  string                 `protobuf:"bytes,13,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"` // 错误信息
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`      // 名称
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`      // 国家代码
+	Ranges        int32                  `protobuf:"varint,3,opt,name=ranges,proto3" json:"ranges,omitempty"` // 子范围数量
+	Share         float64                `protobuf:"fixed64,4,opt,name=share,proto3" json:"share,omitempty"`  // 地址数量占比
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *IPInfo) Reset() {
-	*x = IPInfo{}
+func (x *CIDRSummaryItem) Reset() {
+	*x = CIDRSummaryItem{}
 	mi := &file_api_proto_ipquery_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *IPInfo) String() string {
+func (x *CIDRSummaryItem) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*IPInfo) ProtoMessage() {}
+func (*CIDRSummaryItem) ProtoMessage() {}
 
-func (x *IPInfo) ProtoReflect() protoreflect.Message {
+func (x *CIDRSummaryItem) ProtoReflect() protoreflect.Message {
 	mi := &file_api_proto_ipquery_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -368,144 +423,1600 @@ func (x *IPInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use IPInfo.ProtoReflect.Descriptor instead.
-func (*IPInfo) Descriptor() ([]byte, []int) {
+// Deprecated: Use CIDRSummaryItem.ProtoReflect.Descriptor instead.
+func (*CIDRSummaryItem) Descriptor() ([]byte, []int) {
 	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *IPInfo) GetIp() string {
+func (x *CIDRSummaryItem) GetName() string {
 	if x != nil {
-		return x.Ip
+		return x.Name
 	}
 	return ""
 }
 
-func (x *IPInfo) GetCountry() string {
+func (x *CIDRSummaryItem) GetCode() string {
 	if x != nil {
-		return x.Country
+		return x.Code
 	}
 	return ""
 }
 
-func (x *IPInfo) GetCountryCode() string {
+func (x *CIDRSummaryItem) GetRanges() int32 {
 	if x != nil {
-		return x.CountryCode
+		return x.Ranges
+	}
+	return 0
+}
+
+func (x *CIDRSummaryItem) GetShare() float64 {
+	if x != nil {
+		return x.Share
+	}
+	return 0
+}
+
+// 反查IP段请求，空字段不参与匹配，至少需要一个条件
+type FindRangesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Country       string                 `protobuf:"bytes,1,opt,name=country,proto3" json:"country,omitempty"` // 国家名称或ISO代码
+	Region        string                 `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`   // 省份/州
+	City          string                 `protobuf:"bytes,3,opt,name=city,proto3" json:"city,omitempty"`       // 城市
+	Isp           string                 `protobuf:"bytes,4,opt,name=isp,proto3" json:"isp,omitempty"`         // ISP
+	Lang          string                 `protobuf:"bytes,5,opt,name=lang,proto3" json:"lang,omitempty"`       // 响应语言: zh-CN(默认), en
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindRangesRequest) Reset() {
+	*x = FindRangesRequest{}
+	mi := &file_api_proto_ipquery_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindRangesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindRangesRequest) ProtoMessage() {}
+
+func (x *FindRangesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_ipquery_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindRangesRequest.ProtoReflect.Descriptor instead.
+func (*FindRangesRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *FindRangesRequest) GetCountry() string {
+	if x != nil {
+		return x.Country
 	}
 	return ""
 }
 
-func (x *IPInfo) GetRegion() string {
+func (x *FindRangesRequest) GetRegion() string {
 	if x != nil {
 		return x.Region
 	}
 	return ""
 }
 
-func (x *IPInfo) GetCity() string {
+func (x *FindRangesRequest) GetCity() string {
 	if x != nil {
 		return x.City
 	}
 	return ""
 }
 
-func (x *IPInfo) GetDistrict() string {
+func (x *FindRangesRequest) GetIsp() string {
 	if x != nil {
-		return x.District
+		return x.Isp
 	}
 	return ""
 }
 
-func (x *IPInfo) GetIsp() string {
+func (x *FindRangesRequest) GetLang() string {
 	if x != nil {
-		return x.Isp
+		return x.Lang
 	}
 	return ""
 }
 
-func (x *IPInfo) GetLatitude() float32 {
+// 获取服务状态请求
+type GetServiceStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetServiceStatusRequest) Reset() {
+	*x = GetServiceStatusRequest{}
+	mi := &file_api_proto_ipquery_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetServiceStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetServiceStatusRequest) ProtoMessage() {}
+
+func (x *GetServiceStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_ipquery_proto_msgTypes[8]
 	if x != nil {
-		return x.Latitude
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetServiceStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetServiceStatusRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{8}
+}
+
+// 获取服务状态响应
+type GetServiceStatusResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Status         string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`                                        // 服务状态
+	Version        string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`                                      // 服务版本
+	Uptime         int64                  `protobuf:"varint,3,opt,name=uptime,proto3" json:"uptime,omitempty"`                                       // 运行时间(秒)
+	QueryCount     int64                  `protobuf:"varint,4,opt,name=query_count,json=queryCount,proto3" json:"query_count,omitempty"`             // 查询次数
+	BuildTime      string                 `protobuf:"bytes,5,opt,name=build_time,json=buildTime,proto3" json:"build_time,omitempty"`                 // 服务构建时间
+	GitCommit      string                 `protobuf:"bytes,6,opt,name=git_commit,json=gitCommit,proto3" json:"git_commit,omitempty"`                 // 服务构建时的Git提交
+	Database       *DatabaseInfo          `protobuf:"bytes,7,opt,name=database,proto3" json:"database,omitempty"`                                    // 当前使用的IP数据库
+	CollapsedCount int64                  `protobuf:"varint,8,opt,name=collapsed_count,json=collapsedCount,proto3" json:"collapsed_count,omitempty"` // 与进行中的相同查询合并的次数
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetServiceStatusResponse) Reset() {
+	*x = GetServiceStatusResponse{}
+	mi := &file_api_proto_ipquery_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetServiceStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetServiceStatusResponse) ProtoMessage() {}
+
+func (x *GetServiceStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_ipquery_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetServiceStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetServiceStatusResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetServiceStatusResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *GetServiceStatusResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *GetServiceStatusResponse) GetUptime() int64 {
+	if x != nil {
+		return x.Uptime
 	}
 	return 0
 }
 
-func (x *IPInfo) GetLongitude() float32 {
+func (x *GetServiceStatusResponse) GetQueryCount() int64 {
 	if x != nil {
-		return x.Longitude
+		return x.QueryCount
 	}
 	return 0
 }
 
-func (x *IPInfo) GetTimezone() string {
+func (x *GetServiceStatusResponse) GetBuildTime() string {
 	if x != nil {
-		return x.Timezone
+		return x.BuildTime
 	}
 	return ""
 }
 
-func (x *IPInfo) GetPostalCode() string {
+func (x *GetServiceStatusResponse) GetGitCommit() string {
 	if x != nil {
-		return x.PostalCode
+		return x.GitCommit
 	}
 	return ""
 }
 
-func (x *IPInfo) GetIsValid() bool {
+func (x *GetServiceStatusResponse) GetDatabase() *DatabaseInfo {
 	if x != nil {
-		return x.IsValid
+		return x.Database
 	}
-	return false
+	return nil
 }
 
-func (x *IPInfo) GetErrorMessage() string {
+func (x *GetServiceStatusResponse) GetCollapsedCount() int64 {
 	if x != nil {
-		return x.ErrorMessage
+		return x.CollapsedCount
 	}
-	return ""
+	return 0
 }
 
-var File_api_proto_ipquery_proto protoreflect.FileDescriptor
+// 缓存统计，不适用于某种缓存类型的字段为0
+type CacheStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`     // 缓存类型: memory, redis, tiered
+	Policy        string                 `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"` // 淘汰策略
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`    // 条目数
+	Bytes         int64                  `protobuf:"varint,4,opt,name=bytes,proto3" json:"bytes,omitempty"`  // 条目估算占用的内存
+	MaxSize       int64                  `protobuf:"varint,5,opt,name=max_size,json=maxSize,proto3" json:"max_size,omitempty"`
+	MaxBytes      int64                  `protobuf:"varint,6,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	Hits          uint64                 `protobuf:"varint,7,opt,name=hits,proto3" json:"hits,omitempty"`
+	Misses        uint64                 `protobuf:"varint,8,opt,name=misses,proto3" json:"misses,omitempty"`
+	Evictions     uint64                 `protobuf:"varint,9,opt,name=evictions,proto3" json:"evictions,omitempty"`      // 因超出容量被淘汰的条目数
+	Expirations   uint64                 `protobuf:"varint,10,opt,name=expirations,proto3" json:"expirations,omitempty"` // 因过期被删除的条目数
+	Rejections    uint64                 `protobuf:"varint,11,opt,name=rejections,proto3" json:"rejections,omitempty"`   // 未被准入或超过内存上限而未缓存的条目数
+	Errors        uint64                 `protobuf:"varint,12,opt,name=errors,proto3" json:"errors,omitempty"`           // 访问缓存后端失败的次数
+	Tiers         []*CacheStats          `protobuf:"bytes,13,rep,name=tiers,proto3" json:"tiers,omitempty"`              // 两级缓存中各级的统计
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_api_proto_ipquery_proto_rawDesc = "" +
-	"\n" +
-	"\x17api/proto/ipquery.proto\x12\aipquery\" \n" +
-	"\x0eQueryIPRequest\x12\x0e\n" +
-	"\x02ip\x18\x01 \x01(\tR\x02ip\"T\n" +
-	"\x0fQueryIPResponse\x12#\n" +
-	"\x04info\x18\x01 \x01(\v2\x0f.ipquery.IPInfoR\x04info\x12\x1c\n" +
-	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\"'\n" +
-	"\x13BatchQueryIPRequest\x12\x10\n" +
-	"\x03ips\x18\x01 \x03(\tR\x03ips\"[\n" +
-	"\x14BatchQueryIPResponse\x12%\n" +
-	"\x05infos\x18\x01 \x03(\v2\x0f.ipquery.IPInfoR\x05infos\x12\x1c\n" +
-	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\"\x19\n" +
-	"\x17GetServiceStatusRequest\"\x85\x01\n" +
-	"\x18GetServiceStatusResponse\x12\x16\n" +
-	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
-	"\aversion\x18\x02 \x01(\tR\aversion\x12\x16\n" +
-	"\x06uptime\x18\x03 \x01(\x03R\x06uptime\x12\x1f\n" +
-	"\vquery_count\x18\x04 \x01(\x03R\n" +
-	"queryCount\"\xe6\x02\n" +
-	"\x06IPInfo\x12\x0e\n" +
-	"\x02ip\x18\x01 \x01(\tR\x02ip\x12\x18\n" +
-	"\acountry\x18\x02 \x01(\tR\acountry\x12!\n" +
-	"\fcountry_code\x18\x03 \x01(\tR\vcountryCode\x12\x16\n" +
-	"\x06region\x18\x04 \x01(\tR\x06region\x12\x12\n" +
-	"\x04city\x18\x05 \x01(\tR\x04city\x12\x1a\n" +
-	"\bdistrict\x18\x06 \x01(\tR\bdistrict\x12\x10\n" +
-	"\x03isp\x18\a \x01(\tR\x03isp\x12\x1a\n" +
-	"\blatitude\x18\b \x01(\x02R\blatitude\x12\x1c\n" +
-	"\tlongitude\x18\t \x01(\x02R\tlongitude\x12\x1a\n" +
-	"\btimezone\x18\n" +
+func (x *CacheStats) Reset() {
+	*x = CacheStats{}
+	mi := &file_api_proto_ipquery_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheStats) ProtoMessage() {}
+
+func (x *CacheStats) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_ipquery_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheStats.ProtoReflect.Descriptor instead.
+func (*CacheStats) Descriptor() ([]byte, []int) {
+	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CacheStats) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *CacheStats) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *CacheStats) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *CacheStats) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *CacheStats) GetMaxSize() int64 {
+	if x != nil {
+		return x.MaxSize
+	}
+	return 0
+}
+
+func (x *CacheStats) GetMaxBytes() int64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+func (x *CacheStats) GetHits() uint64 {
+	if x != nil {
+		return x.Hits
+	}
+	return 0
+}
+
+func (x *CacheStats) GetMisses() uint64 {
+	if x != nil {
+		return x.Misses
+	}
+	return 0
+}
+
+func (x *CacheStats) GetEvictions() uint64 {
+	if x != nil {
+		return x.Evictions
+	}
+	return 0
+}
+
+func (x *CacheStats) GetExpirations() uint64 {
+	if x != nil {
+		return x.Expirations
+	}
+	return 0
+}
+
+func (x *CacheStats) GetRejections() uint64 {
+	if x != nil {
+		return x.Rejections
+	}
+	return 0
+}
+
+func (x *CacheStats) GetErrors() uint64 {
+	if x != nil {
+		return x.Errors
+	}
+	return 0
+}
+
+func (x *CacheStats) GetTiers() []*CacheStats {
+	if x != nil {
+		return x.Tiers
+	}
+	return nil
+}
+
+// 获取缓存统计请求
+type GetCacheStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCacheStatsRequest) Reset() {
+	*x = GetCacheStatsRequest{}
+	mi := &file_api_proto_ipquery_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCacheStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCacheStatsRequest) ProtoMessage() {}
+
+func (x *GetCacheStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_ipquery_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCacheStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetCacheStatsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{11}
+}
+
+// 获取缓存统计响应
+type GetCacheStatsResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Stats          *CacheStats            `protobuf:"bytes,1,opt,name=stats,proto3" json:"stats,omitempty"`
+	HitRate        float64                `protobuf:"fixed64,2,opt,name=hit_rate,json=hitRate,proto3" json:"hit_rate,omitempty"`                     // 命中次数占缓存读取次数的比例
+	MissRate       float64                `protobuf:"fixed64,3,opt,name=miss_rate,json=missRate,proto3" json:"miss_rate,omitempty"`                  // 未命中次数占缓存读取次数的比例
+	EvictionRate   float64                `protobuf:"fixed64,4,opt,name=eviction_rate,json=evictionRate,proto3" json:"eviction_rate,omitempty"`      // 自启动以来平均每秒淘汰的条目数
+	CollapsedCount int64                  `protobuf:"varint,5,opt,name=collapsed_count,json=collapsedCount,proto3" json:"collapsed_count,omitempty"` // 与进行中的相同查询合并的次数
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetCacheStatsResponse) Reset() {
+	*x = GetCacheStatsResponse{}
+	mi := &file_api_proto_ipquery_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCacheStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCacheStatsResponse) ProtoMessage() {}
+
+func (x *GetCacheStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_ipquery_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCacheStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetCacheStatsResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetCacheStatsResponse) GetStats() *CacheStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *GetCacheStatsResponse) GetHitRate() float64 {
+	if x != nil {
+		return x.HitRate
+	}
+	return 0
+}
+
+func (x *GetCacheStatsResponse) GetMissRate() float64 {
+	if x != nil {
+		return x.MissRate
+	}
+	return 0
+}
+
+func (x *GetCacheStatsResponse) GetEvictionRate() float64 {
+	if x != nil {
+		return x.EvictionRate
+	}
+	return 0
+}
+
+func (x *GetCacheStatsResponse) GetCollapsedCount() int64 {
+	if x != nil {
+		return x.CollapsedCount
+	}
+	return 0
+}
+
+// 缓存条目
+type CachedEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`                               // 缓存键
+	Cache         string                 `protobuf:"bytes,2,opt,name=cache,proto3" json:"cache,omitempty"`                           // 条目所在的缓存类型
+	Info          *IPInfo                `protobuf:"bytes,3,opt,name=info,proto3" json:"info,omitempty"`                             // 缓存的IP信息
+	ExpiresAt     int64                  `protobuf:"varint,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // 过期时间(Unix秒)，0表示永不过期
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CachedEntry) Reset() {
+	*x = CachedEntry{}
+	mi := &file_api_proto_ipquery_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CachedEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CachedEntry) ProtoMessage() {}
+
+func (x *CachedEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_ipquery_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CachedEntry.ProtoReflect.Descriptor instead.
+func (*CachedEntry) Descriptor() ([]byte, []int) {
+	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CachedEntry) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *CachedEntry) GetCache() string {
+	if x != nil {
+		return x.Cache
+	}
+	return ""
+}
+
+func (x *CachedEntry) GetInfo() *IPInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+func (x *CachedEntry) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+// 查看缓存条目请求
+type InspectCacheRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ip            string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`     // IP地址
+	Lang          string                 `protobuf:"bytes,2,opt,name=lang,proto3" json:"lang,omitempty"` // 响应语言: zh-CN(默认), en
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InspectCacheRequest) Reset() {
+	*x = InspectCacheRequest{}
+	mi := &file_api_proto_ipquery_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InspectCacheRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InspectCacheRequest) ProtoMessage() {}
+
+func (x *InspectCacheRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_ipquery_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InspectCacheRequest.ProtoReflect.Descriptor instead.
+func (*InspectCacheRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *InspectCacheRequest) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *InspectCacheRequest) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+// 查看缓存条目响应，未缓存时entries为空
+type InspectCacheResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*CachedEntry         `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InspectCacheResponse) Reset() {
+	*x = InspectCacheResponse{}
+	mi := &file_api_proto_ipquery_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InspectCacheResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InspectCacheResponse) ProtoMessage() {}
+
+func (x *InspectCacheResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_ipquery_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InspectCacheResponse.ProtoReflect.Descriptor instead.
+func (*InspectCacheResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *InspectCacheResponse) GetEntries() []*CachedEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+// 删除缓存条目请求
+type DeleteCacheEntryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ip            string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"` // IP地址
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCacheEntryRequest) Reset() {
+	*x = DeleteCacheEntryRequest{}
+	mi := &file_api_proto_ipquery_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCacheEntryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCacheEntryRequest) ProtoMessage() {}
+
+func (x *DeleteCacheEntryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_ipquery_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCacheEntryRequest.ProtoReflect.Descriptor instead.
+func (*DeleteCacheEntryRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *DeleteCacheEntryRequest) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+// 删除缓存条目响应
+type DeleteCacheEntryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCacheEntryResponse) Reset() {
+	*x = DeleteCacheEntryResponse{}
+	mi := &file_api_proto_ipquery_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCacheEntryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCacheEntryResponse) ProtoMessage() {}
+
+func (x *DeleteCacheEntryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_ipquery_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCacheEntryResponse.ProtoReflect.Descriptor instead.
+func (*DeleteCacheEntryResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{17}
+}
+
+// 清除网段缓存请求
+type PurgeCacheRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prefix        string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"` // 网段，如 1.2.3.0/24
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurgeCacheRequest) Reset() {
+	*x = PurgeCacheRequest{}
+	mi := &file_api_proto_ipquery_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeCacheRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeCacheRequest) ProtoMessage() {}
+
+func (x *PurgeCacheRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_ipquery_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeCacheRequest.ProtoReflect.Descriptor instead.
+func (*PurgeCacheRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *PurgeCacheRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+// 清除网段缓存响应
+type PurgeCacheResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Deleted       int64                  `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"` // 删除的条目数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurgeCacheResponse) Reset() {
+	*x = PurgeCacheResponse{}
+	mi := &file_api_proto_ipquery_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeCacheResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeCacheResponse) ProtoMessage() {}
+
+func (x *PurgeCacheResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_ipquery_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeCacheResponse.ProtoReflect.Descriptor instead.
+func (*PurgeCacheResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *PurgeCacheResponse) GetDeleted() int64 {
+	if x != nil {
+		return x.Deleted
+	}
+	return 0
+}
+
+// 清空缓存请求
+type FlushCacheRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FlushCacheRequest) Reset() {
+	*x = FlushCacheRequest{}
+	mi := &file_api_proto_ipquery_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlushCacheRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlushCacheRequest) ProtoMessage() {}
+
+func (x *FlushCacheRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_ipquery_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlushCacheRequest.ProtoReflect.Descriptor instead.
+func (*FlushCacheRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{20}
+}
+
+// 清空缓存响应
+type FlushCacheResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FlushCacheResponse) Reset() {
+	*x = FlushCacheResponse{}
+	mi := &file_api_proto_ipquery_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlushCacheResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlushCacheResponse) ProtoMessage() {}
+
+func (x *FlushCacheResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_ipquery_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlushCacheResponse.ProtoReflect.Descriptor instead.
+func (*FlushCacheResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{21}
+}
+
+// IP数据库来源信息
+type DatabaseInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`                          // 数据源类型(ip_database.type)
+	Files         []*DatabaseFile        `protobuf:"bytes,2,rep,name=files,proto3" json:"files,omitempty"`                        // 数据文件
+	LoadedAt      int64                  `protobuf:"varint,3,opt,name=loaded_at,json=loadedAt,proto3" json:"loaded_at,omitempty"` // 加载时间(Unix秒)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DatabaseInfo) Reset() {
+	*x = DatabaseInfo{}
+	mi := &file_api_proto_ipquery_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DatabaseInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DatabaseInfo) ProtoMessage() {}
+
+func (x *DatabaseInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_ipquery_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DatabaseInfo.ProtoReflect.Descriptor instead.
+func (*DatabaseInfo) Descriptor() ([]byte, []int) {
+	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *DatabaseInfo) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *DatabaseInfo) GetFiles() []*DatabaseFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *DatabaseInfo) GetLoadedAt() int64 {
+	if x != nil {
+		return x.LoadedAt
+	}
+	return 0
+}
+
+// 数据文件来源信息
+type DatabaseFile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`                             // 文件路径
+	Format        string                 `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`                         // 文件格式，如ip2region-xdb、GeoLite2-City
+	Version       string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`                       // 文件格式版本
+	BuildTime     int64                  `protobuf:"varint,4,opt,name=build_time,json=buildTime,proto3" json:"build_time,omitempty"` // 数据文件中记录的构建时间(Unix秒)，0表示未记录
+	Records       int64                  `protobuf:"varint,5,opt,name=records,proto3" json:"records,omitempty"`                      // IP段记录数
+	Size          int64                  `protobuf:"varint,6,opt,name=size,proto3" json:"size,omitempty"`                            // 文件大小(字节)
+	Sha256        string                 `protobuf:"bytes,7,opt,name=sha256,proto3" json:"sha256,omitempty"`                         // 文件SHA-256
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DatabaseFile) Reset() {
+	*x = DatabaseFile{}
+	mi := &file_api_proto_ipquery_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DatabaseFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DatabaseFile) ProtoMessage() {}
+
+func (x *DatabaseFile) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_ipquery_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DatabaseFile.ProtoReflect.Descriptor instead.
+func (*DatabaseFile) Descriptor() ([]byte, []int) {
+	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *DatabaseFile) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DatabaseFile) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *DatabaseFile) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *DatabaseFile) GetBuildTime() int64 {
+	if x != nil {
+		return x.BuildTime
+	}
+	return 0
+}
+
+func (x *DatabaseFile) GetRecords() int64 {
+	if x != nil {
+		return x.Records
+	}
+	return 0
+}
+
+func (x *DatabaseFile) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *DatabaseFile) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+// IP信息
+type IPInfo struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Ip             string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`                                                // IP地址
+	Country        string                 `protobuf:"bytes,2,opt,name=country,proto3" json:"country,omitempty"`                                      // 国家
+	CountryCode    string                 `protobuf:"bytes,3,opt,name=country_code,json=countryCode,proto3" json:"country_code,omitempty"`           // 国家代码
+	Region         string                 `protobuf:"bytes,4,opt,name=region,proto3" json:"region,omitempty"`                                        // 省份/州
+	City           string                 `protobuf:"bytes,5,opt,name=city,proto3" json:"city,omitempty"`                                            // 城市
+	District       string                 `protobuf:"bytes,6,opt,name=district,proto3" json:"district,omitempty"`                                    // 区县
+	Isp            string                 `protobuf:"bytes,7,opt,name=isp,proto3" json:"isp,omitempty"`                                              // ISP
+	Latitude       float32                `protobuf:"fixed32,8,opt,name=latitude,proto3" json:"latitude,omitempty"`                                  // 纬度
+	Longitude      float32                `protobuf:"fixed32,9,opt,name=longitude,proto3" json:"longitude,omitempty"`                                // 经度
+	Timezone       string                 `protobuf:"bytes,10,opt,name=timezone,proto3" json:"timezone,omitempty"`                                   // 时区
+	PostalCode     string                 `protobuf:"bytes,11,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`             // 邮政编码
+	IsValid        bool                   `protobuf:"varint,12,opt,name=is_valid,json=isValid,proto3" json:"is_valid,omitempty"`                     // 是否有效IP
+	ErrorMessage   string                 `protobuf:"bytes,13,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`       // 错误信息
+	Range          *IPRange               `protobuf:"bytes,14,opt,name=range,proto3" json:"range,omitempty"`                                         // 命中的IP范围
+	AddressType    string                 `protobuf:"bytes,15,opt,name=address_type,json=addressType,proto3" json:"address_type,omitempty"`          // 地址类型: private, loopback, link_local, cgnat, multicast, reserved, documentation, global
+	Scope          string                 `protobuf:"bytes,16,opt,name=scope,proto3" json:"scope,omitempty"`                                         // 特殊用途地址块名称
+	Tags           []string               `protobuf:"bytes,17,rep,name=tags,proto3" json:"tags,omitempty"`                                           // 覆盖表中的自定义标签
+	Asn            uint32                 `protobuf:"varint,18,opt,name=asn,proto3" json:"asn,omitempty"`                                            // 自治系统号
+	AsOrganization string                 `protobuf:"bytes,19,opt,name=as_organization,json=asOrganization,proto3" json:"as_organization,omitempty"` // 自治系统组织
+	AsPrefix       string                 `protobuf:"bytes,20,opt,name=as_prefix,json=asPrefix,proto3" json:"as_prefix,omitempty"`                   // 包含该地址的宣告网段
+	AdminCode      string                 `protobuf:"bytes,21,opt,name=admin_code,json=adminCode,proto3" json:"admin_code,omitempty"`                // 行政区划代码
+	Threat         *ThreatInfo            `protobuf:"bytes,22,opt,name=threat,proto3" json:"threat,omitempty"`                                       // IP信誉信息
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *IPInfo) Reset() {
+	*x = IPInfo{}
+	mi := &file_api_proto_ipquery_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IPInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IPInfo) ProtoMessage() {}
+
+func (x *IPInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_ipquery_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IPInfo.ProtoReflect.Descriptor instead.
+func (*IPInfo) Descriptor() ([]byte, []int) {
+	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *IPInfo) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *IPInfo) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *IPInfo) GetCountryCode() string {
+	if x != nil {
+		return x.CountryCode
+	}
+	return ""
+}
+
+func (x *IPInfo) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *IPInfo) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *IPInfo) GetDistrict() string {
+	if x != nil {
+		return x.District
+	}
+	return ""
+}
+
+func (x *IPInfo) GetIsp() string {
+	if x != nil {
+		return x.Isp
+	}
+	return ""
+}
+
+func (x *IPInfo) GetLatitude() float32 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *IPInfo) GetLongitude() float32 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *IPInfo) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *IPInfo) GetPostalCode() string {
+	if x != nil {
+		return x.PostalCode
+	}
+	return ""
+}
+
+func (x *IPInfo) GetIsValid() bool {
+	if x != nil {
+		return x.IsValid
+	}
+	return false
+}
+
+func (x *IPInfo) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *IPInfo) GetRange() *IPRange {
+	if x != nil {
+		return x.Range
+	}
+	return nil
+}
+
+func (x *IPInfo) GetAddressType() string {
+	if x != nil {
+		return x.AddressType
+	}
+	return ""
+}
+
+func (x *IPInfo) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *IPInfo) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *IPInfo) GetAsn() uint32 {
+	if x != nil {
+		return x.Asn
+	}
+	return 0
+}
+
+func (x *IPInfo) GetAsOrganization() string {
+	if x != nil {
+		return x.AsOrganization
+	}
+	return ""
+}
+
+func (x *IPInfo) GetAsPrefix() string {
+	if x != nil {
+		return x.AsPrefix
+	}
+	return ""
+}
+
+func (x *IPInfo) GetAdminCode() string {
+	if x != nil {
+		return x.AdminCode
+	}
+	return ""
+}
+
+func (x *IPInfo) GetThreat() *ThreatInfo {
+	if x != nil {
+		return x.Threat
+	}
+	return nil
+}
+
+// IP信誉信息
+type ThreatInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IsHosting     bool                   `protobuf:"varint,1,opt,name=is_hosting,json=isHosting,proto3" json:"is_hosting,omitempty"` // 数据中心/托管
+	IsVpn         bool                   `protobuf:"varint,2,opt,name=is_vpn,json=isVpn,proto3" json:"is_vpn,omitempty"`             // VPN服务
+	IsProxy       bool                   `protobuf:"varint,3,opt,name=is_proxy,json=isProxy,proto3" json:"is_proxy,omitempty"`       // 公共代理
+	IsTor         bool                   `protobuf:"varint,4,opt,name=is_tor,json=isTor,proto3" json:"is_tor,omitempty"`             // Tor出口节点
+	UsageType     string                 `protobuf:"bytes,5,opt,name=usage_type,json=usageType,proto3" json:"usage_type,omitempty"`  // 命中的最高优先级类别
+	Sources       []string               `protobuf:"bytes,6,rep,name=sources,proto3" json:"sources,omitempty"`                       // 命中的列表名称
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ThreatInfo) Reset() {
+	*x = ThreatInfo{}
+	mi := &file_api_proto_ipquery_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ThreatInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ThreatInfo) ProtoMessage() {}
+
+func (x *ThreatInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_ipquery_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ThreatInfo.ProtoReflect.Descriptor instead.
+func (*ThreatInfo) Descriptor() ([]byte, []int) {
+	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ThreatInfo) GetIsHosting() bool {
+	if x != nil {
+		return x.IsHosting
+	}
+	return false
+}
+
+func (x *ThreatInfo) GetIsVpn() bool {
+	if x != nil {
+		return x.IsVpn
+	}
+	return false
+}
+
+func (x *ThreatInfo) GetIsProxy() bool {
+	if x != nil {
+		return x.IsProxy
+	}
+	return false
+}
+
+func (x *ThreatInfo) GetIsTor() bool {
+	if x != nil {
+		return x.IsTor
+	}
+	return false
+}
+
+func (x *ThreatInfo) GetUsageType() string {
+	if x != nil {
+		return x.UsageType
+	}
+	return ""
+}
+
+func (x *ThreatInfo) GetSources() []string {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+// IP范围
+type IPRange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Start         string                 `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"` // 起始IP
+	End           string                 `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`     // 结束IP
+	Cidrs         []string               `protobuf:"bytes,3,rep,name=cidrs,proto3" json:"cidrs,omitempty"` // 恰好覆盖该范围的最少CIDR列表
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IPRange) Reset() {
+	*x = IPRange{}
+	mi := &file_api_proto_ipquery_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IPRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IPRange) ProtoMessage() {}
+
+func (x *IPRange) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_ipquery_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IPRange.ProtoReflect.Descriptor instead.
+func (*IPRange) Descriptor() ([]byte, []int) {
+	return file_api_proto_ipquery_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *IPRange) GetStart() string {
+	if x != nil {
+		return x.Start
+	}
+	return ""
+}
+
+func (x *IPRange) GetEnd() string {
+	if x != nil {
+		return x.End
+	}
+	return ""
+}
+
+func (x *IPRange) GetCidrs() []string {
+	if x != nil {
+		return x.Cidrs
+	}
+	return nil
+}
+
+var File_api_proto_ipquery_proto protoreflect.FileDescriptor
+
+const file_api_proto_ipquery_proto_rawDesc = "" +
+	"\n" +
+	"\x17api/proto/ipquery.proto\x12\aipquery\"4\n" +
+	"\x0eQueryIPRequest\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\x12\x12\n" +
+	"\x04lang\x18\x02 \x01(\tR\x04lang\"T\n" +
+	"\x0fQueryIPResponse\x12#\n" +
+	"\x04info\x18\x01 \x01(\v2\x0f.ipquery.IPInfoR\x04info\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\";\n" +
+	"\x13BatchQueryIPRequest\x12\x10\n" +
+	"\x03ips\x18\x01 \x03(\tR\x03ips\x12\x12\n" +
+	"\x04lang\x18\x02 \x01(\tR\x04lang\"[\n" +
+	"\x14BatchQueryIPResponse\x12%\n" +
+	"\x05infos\x18\x01 \x03(\v2\x0f.ipquery.IPInfoR\x05infos\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\"T\n" +
+	"\x10QueryCIDRRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\x12\x12\n" +
+	"\x04lang\x18\x02 \x01(\tR\x04lang\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"\x99\x02\n" +
+	"\x11QueryCIDRResponse\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\x12'\n" +
+	"\x06ranges\x18\x02 \x03(\v2\x0f.ipquery.IPInfoR\x06ranges\x12!\n" +
+	"\ftotal_ranges\x18\x03 \x01(\x05R\vtotalRanges\x12\x1c\n" +
+	"\ttruncated\x18\x04 \x01(\bR\ttruncated\x126\n" +
+	"\tcountries\x18\x05 \x03(\v2\x18.ipquery.CIDRSummaryItemR\tcountries\x12,\n" +
+	"\x04isps\x18\x06 \x03(\v2\x18.ipquery.CIDRSummaryItemR\x04isps\x12\x1c\n" +
+	"\ttimestamp\x18\a \x01(\x03R\ttimestamp\"g\n" +
+	"\x0fCIDRSummaryItem\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12\x16\n" +
+	"\x06ranges\x18\x03 \x01(\x05R\x06ranges\x12\x14\n" +
+	"\x05share\x18\x04 \x01(\x01R\x05share\"\x7f\n" +
+	"\x11FindRangesRequest\x12\x18\n" +
+	"\acountry\x18\x01 \x01(\tR\acountry\x12\x16\n" +
+	"\x06region\x18\x02 \x01(\tR\x06region\x12\x12\n" +
+	"\x04city\x18\x03 \x01(\tR\x04city\x12\x10\n" +
+	"\x03isp\x18\x04 \x01(\tR\x03isp\x12\x12\n" +
+	"\x04lang\x18\x05 \x01(\tR\x04lang\"\x19\n" +
+	"\x17GetServiceStatusRequest\"\x9f\x02\n" +
+	"\x18GetServiceStatusResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x16\n" +
+	"\x06uptime\x18\x03 \x01(\x03R\x06uptime\x12\x1f\n" +
+	"\vquery_count\x18\x04 \x01(\x03R\n" +
+	"queryCount\x12\x1d\n" +
+	"\n" +
+	"build_time\x18\x05 \x01(\tR\tbuildTime\x12\x1d\n" +
+	"\n" +
+	"git_commit\x18\x06 \x01(\tR\tgitCommit\x121\n" +
+	"\bdatabase\x18\a \x01(\v2\x15.ipquery.DatabaseInfoR\bdatabase\x12'\n" +
+	"\x0fcollapsed_count\x18\b \x01(\x03R\x0ecollapsedCount\"\xe9\x02\n" +
+	"\n" +
+	"CacheStats\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x16\n" +
+	"\x06policy\x18\x02 \x01(\tR\x06policy\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x14\n" +
+	"\x05bytes\x18\x04 \x01(\x03R\x05bytes\x12\x19\n" +
+	"\bmax_size\x18\x05 \x01(\x03R\amaxSize\x12\x1b\n" +
+	"\tmax_bytes\x18\x06 \x01(\x03R\bmaxBytes\x12\x12\n" +
+	"\x04hits\x18\a \x01(\x04R\x04hits\x12\x16\n" +
+	"\x06misses\x18\b \x01(\x04R\x06misses\x12\x1c\n" +
+	"\tevictions\x18\t \x01(\x04R\tevictions\x12 \n" +
+	"\vexpirations\x18\n" +
+	" \x01(\x04R\vexpirations\x12\x1e\n" +
+	"\n" +
+	"rejections\x18\v \x01(\x04R\n" +
+	"rejections\x12\x16\n" +
+	"\x06errors\x18\f \x01(\x04R\x06errors\x12)\n" +
+	"\x05tiers\x18\r \x03(\v2\x13.ipquery.CacheStatsR\x05tiers\"\x16\n" +
+	"\x14GetCacheStatsRequest\"\xc8\x01\n" +
+	"\x15GetCacheStatsResponse\x12)\n" +
+	"\x05stats\x18\x01 \x01(\v2\x13.ipquery.CacheStatsR\x05stats\x12\x19\n" +
+	"\bhit_rate\x18\x02 \x01(\x01R\ahitRate\x12\x1b\n" +
+	"\tmiss_rate\x18\x03 \x01(\x01R\bmissRate\x12#\n" +
+	"\reviction_rate\x18\x04 \x01(\x01R\fevictionRate\x12'\n" +
+	"\x0fcollapsed_count\x18\x05 \x01(\x03R\x0ecollapsedCount\"y\n" +
+	"\vCachedEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05cache\x18\x02 \x01(\tR\x05cache\x12#\n" +
+	"\x04info\x18\x03 \x01(\v2\x0f.ipquery.IPInfoR\x04info\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\x03R\texpiresAt\"9\n" +
+	"\x13InspectCacheRequest\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\x12\x12\n" +
+	"\x04lang\x18\x02 \x01(\tR\x04lang\"F\n" +
+	"\x14InspectCacheResponse\x12.\n" +
+	"\aentries\x18\x01 \x03(\v2\x14.ipquery.CachedEntryR\aentries\")\n" +
+	"\x17DeleteCacheEntryRequest\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\"\x1a\n" +
+	"\x18DeleteCacheEntryResponse\"+\n" +
+	"\x11PurgeCacheRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\".\n" +
+	"\x12PurgeCacheResponse\x12\x18\n" +
+	"\adeleted\x18\x01 \x01(\x03R\adeleted\"\x13\n" +
+	"\x11FlushCacheRequest\"\x14\n" +
+	"\x12FlushCacheResponse\"l\n" +
+	"\fDatabaseInfo\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12+\n" +
+	"\x05files\x18\x02 \x03(\v2\x15.ipquery.DatabaseFileR\x05files\x12\x1b\n" +
+	"\tloaded_at\x18\x03 \x01(\x03R\bloadedAt\"\xb9\x01\n" +
+	"\fDatabaseFile\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\x12\x1d\n" +
+	"\n" +
+	"build_time\x18\x04 \x01(\x03R\tbuildTime\x12\x18\n" +
+	"\arecords\x18\x05 \x01(\x03R\arecords\x12\x12\n" +
+	"\x04size\x18\x06 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06sha256\x18\a \x01(\tR\x06sha256\"\xff\x04\n" +
+	"\x06IPInfo\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\x12\x18\n" +
+	"\acountry\x18\x02 \x01(\tR\acountry\x12!\n" +
+	"\fcountry_code\x18\x03 \x01(\tR\vcountryCode\x12\x16\n" +
+	"\x06region\x18\x04 \x01(\tR\x06region\x12\x12\n" +
+	"\x04city\x18\x05 \x01(\tR\x04city\x12\x1a\n" +
+	"\bdistrict\x18\x06 \x01(\tR\bdistrict\x12\x10\n" +
+	"\x03isp\x18\a \x01(\tR\x03isp\x12\x1a\n" +
+	"\blatitude\x18\b \x01(\x02R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\t \x01(\x02R\tlongitude\x12\x1a\n" +
+	"\btimezone\x18\n" +
 	" \x01(\tR\btimezone\x12\x1f\n" +
 	"\vpostal_code\x18\v \x01(\tR\n" +
 	"postalCode\x12\x19\n" +
 	"\bis_valid\x18\f \x01(\bR\aisValid\x12#\n" +
-	"\rerror_message\x18\r \x01(\tR\ferrorMessage2\xf4\x01\n" +
+	"\rerror_message\x18\r \x01(\tR\ferrorMessage\x12&\n" +
+	"\x05range\x18\x0e \x01(\v2\x10.ipquery.IPRangeR\x05range\x12!\n" +
+	"\faddress_type\x18\x0f \x01(\tR\vaddressType\x12\x14\n" +
+	"\x05scope\x18\x10 \x01(\tR\x05scope\x12\x12\n" +
+	"\x04tags\x18\x11 \x03(\tR\x04tags\x12\x10\n" +
+	"\x03asn\x18\x12 \x01(\rR\x03asn\x12'\n" +
+	"\x0fas_organization\x18\x13 \x01(\tR\x0easOrganization\x12\x1b\n" +
+	"\tas_prefix\x18\x14 \x01(\tR\basPrefix\x12\x1d\n" +
+	"\n" +
+	"admin_code\x18\x15 \x01(\tR\tadminCode\x12+\n" +
+	"\x06threat\x18\x16 \x01(\v2\x13.ipquery.ThreatInfoR\x06threat\"\xad\x01\n" +
+	"\n" +
+	"ThreatInfo\x12\x1d\n" +
+	"\n" +
+	"is_hosting\x18\x01 \x01(\bR\tisHosting\x12\x15\n" +
+	"\x06is_vpn\x18\x02 \x01(\bR\x05isVpn\x12\x19\n" +
+	"\bis_proxy\x18\x03 \x01(\bR\aisProxy\x12\x15\n" +
+	"\x06is_tor\x18\x04 \x01(\bR\x05isTor\x12\x1d\n" +
+	"\n" +
+	"usage_type\x18\x05 \x01(\tR\tusageType\x12\x18\n" +
+	"\asources\x18\x06 \x03(\tR\asources\"G\n" +
+	"\aIPRange\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\tR\x05start\x12\x10\n" +
+	"\x03end\x18\x02 \x01(\tR\x03end\x12\x14\n" +
+	"\x05cidrs\x18\x03 \x03(\tR\x05cidrs2\xf9\x05\n" +
 	"\x0eIPQueryService\x12<\n" +
 	"\aQueryIP\x12\x17.ipquery.QueryIPRequest\x1a\x18.ipquery.QueryIPResponse\x12K\n" +
-	"\fBatchQueryIP\x12\x1c.ipquery.BatchQueryIPRequest\x1a\x1d.ipquery.BatchQueryIPResponse\x12W\n" +
-	"\x10GetServiceStatus\x12 .ipquery.GetServiceStatusRequest\x1a!.ipquery.GetServiceStatusResponseB\rZ\v./api/protob\x06proto3"
+	"\fBatchQueryIP\x12\x1c.ipquery.BatchQueryIPRequest\x1a\x1d.ipquery.BatchQueryIPResponse\x12B\n" +
+	"\tQueryCIDR\x12\x19.ipquery.QueryCIDRRequest\x1a\x1a.ipquery.QueryCIDRResponse\x12;\n" +
+	"\n" +
+	"FindRanges\x12\x1a.ipquery.FindRangesRequest\x1a\x0f.ipquery.IPInfo0\x01\x12W\n" +
+	"\x10GetServiceStatus\x12 .ipquery.GetServiceStatusRequest\x1a!.ipquery.GetServiceStatusResponse\x12N\n" +
+	"\rGetCacheStats\x12\x1d.ipquery.GetCacheStatsRequest\x1a\x1e.ipquery.GetCacheStatsResponse\x12K\n" +
+	"\fInspectCache\x12\x1c.ipquery.InspectCacheRequest\x1a\x1d.ipquery.InspectCacheResponse\x12W\n" +
+	"\x10DeleteCacheEntry\x12 .ipquery.DeleteCacheEntryRequest\x1a!.ipquery.DeleteCacheEntryResponse\x12E\n" +
+	"\n" +
+	"PurgeCache\x12\x1a.ipquery.PurgeCacheRequest\x1a\x1b.ipquery.PurgeCacheResponse\x12E\n" +
+	"\n" +
+	"FlushCache\x12\x1a.ipquery.FlushCacheRequest\x1a\x1b.ipquery.FlushCacheResponseB\rZ\v./api/protob\x06proto3"
 
 var (
 	file_api_proto_ipquery_proto_rawDescOnce sync.Once
@@ -519,30 +2030,75 @@ func file_api_proto_ipquery_proto_rawDescGZIP() []byte {
 	return file_api_proto_ipquery_proto_rawDescData
 }
 
-var file_api_proto_ipquery_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_api_proto_ipquery_proto_msgTypes = make([]protoimpl.MessageInfo, 27)
 var file_api_proto_ipquery_proto_goTypes = []any{
 	(*QueryIPRequest)(nil),           // 0: ipquery.QueryIPRequest
 	(*QueryIPResponse)(nil),          // 1: ipquery.QueryIPResponse
 	(*BatchQueryIPRequest)(nil),      // 2: ipquery.BatchQueryIPRequest
 	(*BatchQueryIPResponse)(nil),     // 3: ipquery.BatchQueryIPResponse
-	(*GetServiceStatusRequest)(nil),  // 4: ipquery.GetServiceStatusRequest
-	(*GetServiceStatusResponse)(nil), // 5: ipquery.GetServiceStatusResponse
-	(*IPInfo)(nil),                   // 6: ipquery.IPInfo
+	(*QueryCIDRRequest)(nil),         // 4: ipquery.QueryCIDRRequest
+	(*QueryCIDRResponse)(nil),        // 5: ipquery.QueryCIDRResponse
+	(*CIDRSummaryItem)(nil),          // 6: ipquery.CIDRSummaryItem
+	(*FindRangesRequest)(nil),        // 7: ipquery.FindRangesRequest
+	(*GetServiceStatusRequest)(nil),  // 8: ipquery.GetServiceStatusRequest
+	(*GetServiceStatusResponse)(nil), // 9: ipquery.GetServiceStatusResponse
+	(*CacheStats)(nil),               // 10: ipquery.CacheStats
+	(*GetCacheStatsRequest)(nil),     // 11: ipquery.GetCacheStatsRequest
+	(*GetCacheStatsResponse)(nil),    // 12: ipquery.GetCacheStatsResponse
+	(*CachedEntry)(nil),              // 13: ipquery.CachedEntry
+	(*InspectCacheRequest)(nil),      // 14: ipquery.InspectCacheRequest
+	(*InspectCacheResponse)(nil),     // 15: ipquery.InspectCacheResponse
+	(*DeleteCacheEntryRequest)(nil),  // 16: ipquery.DeleteCacheEntryRequest
+	(*DeleteCacheEntryResponse)(nil), // 17: ipquery.DeleteCacheEntryResponse
+	(*PurgeCacheRequest)(nil),        // 18: ipquery.PurgeCacheRequest
+	(*PurgeCacheResponse)(nil),       // 19: ipquery.PurgeCacheResponse
+	(*FlushCacheRequest)(nil),        // 20: ipquery.FlushCacheRequest
+	(*FlushCacheResponse)(nil),       // 21: ipquery.FlushCacheResponse
+	(*DatabaseInfo)(nil),             // 22: ipquery.DatabaseInfo
+	(*DatabaseFile)(nil),             // 23: ipquery.DatabaseFile
+	(*IPInfo)(nil),                   // 24: ipquery.IPInfo
+	(*ThreatInfo)(nil),               // 25: ipquery.ThreatInfo
+	(*IPRange)(nil),                  // 26: ipquery.IPRange
 }
 var file_api_proto_ipquery_proto_depIdxs = []int32{
-	6, // 0: ipquery.QueryIPResponse.info:type_name -> ipquery.IPInfo
-	6, // 1: ipquery.BatchQueryIPResponse.infos:type_name -> ipquery.IPInfo
-	0, // 2: ipquery.IPQueryService.QueryIP:input_type -> ipquery.QueryIPRequest
-	2, // 3: ipquery.IPQueryService.BatchQueryIP:input_type -> ipquery.BatchQueryIPRequest
-	4, // 4: ipquery.IPQueryService.GetServiceStatus:input_type -> ipquery.GetServiceStatusRequest
-	1, // 5: ipquery.IPQueryService.QueryIP:output_type -> ipquery.QueryIPResponse
-	3, // 6: ipquery.IPQueryService.BatchQueryIP:output_type -> ipquery.BatchQueryIPResponse
-	5, // 7: ipquery.IPQueryService.GetServiceStatus:output_type -> ipquery.GetServiceStatusResponse
-	5, // [5:8] is the sub-list for method output_type
-	2, // [2:5] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	24, // 0: ipquery.QueryIPResponse.info:type_name -> ipquery.IPInfo
+	24, // 1: ipquery.BatchQueryIPResponse.infos:type_name -> ipquery.IPInfo
+	24, // 2: ipquery.QueryCIDRResponse.ranges:type_name -> ipquery.IPInfo
+	6,  // 3: ipquery.QueryCIDRResponse.countries:type_name -> ipquery.CIDRSummaryItem
+	6,  // 4: ipquery.QueryCIDRResponse.isps:type_name -> ipquery.CIDRSummaryItem
+	22, // 5: ipquery.GetServiceStatusResponse.database:type_name -> ipquery.DatabaseInfo
+	10, // 6: ipquery.CacheStats.tiers:type_name -> ipquery.CacheStats
+	10, // 7: ipquery.GetCacheStatsResponse.stats:type_name -> ipquery.CacheStats
+	24, // 8: ipquery.CachedEntry.info:type_name -> ipquery.IPInfo
+	13, // 9: ipquery.InspectCacheResponse.entries:type_name -> ipquery.CachedEntry
+	23, // 10: ipquery.DatabaseInfo.files:type_name -> ipquery.DatabaseFile
+	26, // 11: ipquery.IPInfo.range:type_name -> ipquery.IPRange
+	25, // 12: ipquery.IPInfo.threat:type_name -> ipquery.ThreatInfo
+	0,  // 13: ipquery.IPQueryService.QueryIP:input_type -> ipquery.QueryIPRequest
+	2,  // 14: ipquery.IPQueryService.BatchQueryIP:input_type -> ipquery.BatchQueryIPRequest
+	4,  // 15: ipquery.IPQueryService.QueryCIDR:input_type -> ipquery.QueryCIDRRequest
+	7,  // 16: ipquery.IPQueryService.FindRanges:input_type -> ipquery.FindRangesRequest
+	8,  // 17: ipquery.IPQueryService.GetServiceStatus:input_type -> ipquery.GetServiceStatusRequest
+	11, // 18: ipquery.IPQueryService.GetCacheStats:input_type -> ipquery.GetCacheStatsRequest
+	14, // 19: ipquery.IPQueryService.InspectCache:input_type -> ipquery.InspectCacheRequest
+	16, // 20: ipquery.IPQueryService.DeleteCacheEntry:input_type -> ipquery.DeleteCacheEntryRequest
+	18, // 21: ipquery.IPQueryService.PurgeCache:input_type -> ipquery.PurgeCacheRequest
+	20, // 22: ipquery.IPQueryService.FlushCache:input_type -> ipquery.FlushCacheRequest
+	1,  // 23: ipquery.IPQueryService.QueryIP:output_type -> ipquery.QueryIPResponse
+	3,  // 24: ipquery.IPQueryService.BatchQueryIP:output_type -> ipquery.BatchQueryIPResponse
+	5,  // 25: ipquery.IPQueryService.QueryCIDR:output_type -> ipquery.QueryCIDRResponse
+	24, // 26: ipquery.IPQueryService.FindRanges:output_type -> ipquery.IPInfo
+	9,  // 27: ipquery.IPQueryService.GetServiceStatus:output_type -> ipquery.GetServiceStatusResponse
+	12, // 28: ipquery.IPQueryService.GetCacheStats:output_type -> ipquery.GetCacheStatsResponse
+	15, // 29: ipquery.IPQueryService.InspectCache:output_type -> ipquery.InspectCacheResponse
+	17, // 30: ipquery.IPQueryService.DeleteCacheEntry:output_type -> ipquery.DeleteCacheEntryResponse
+	19, // 31: ipquery.IPQueryService.PurgeCache:output_type -> ipquery.PurgeCacheResponse
+	21, // 32: ipquery.IPQueryService.FlushCache:output_type -> ipquery.FlushCacheResponse
+	23, // [23:33] is the sub-list for method output_type
+	13, // [13:23] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_api_proto_ipquery_proto_init() }
@@ -556,7 +2112,7 @@ func file_api_proto_ipquery_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_ipquery_proto_rawDesc), len(file_api_proto_ipquery_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   27,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -21,7 +21,14 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	IPQueryService_QueryIP_FullMethodName          = "/ipquery.IPQueryService/QueryIP"
 	IPQueryService_BatchQueryIP_FullMethodName     = "/ipquery.IPQueryService/BatchQueryIP"
+	IPQueryService_QueryCIDR_FullMethodName        = "/ipquery.IPQueryService/QueryCIDR"
+	IPQueryService_FindRanges_FullMethodName       = "/ipquery.IPQueryService/FindRanges"
 	IPQueryService_GetServiceStatus_FullMethodName = "/ipquery.IPQueryService/GetServiceStatus"
+	IPQueryService_GetCacheStats_FullMethodName    = "/ipquery.IPQueryService/GetCacheStats"
+	IPQueryService_InspectCache_FullMethodName     = "/ipquery.IPQueryService/InspectCache"
+	IPQueryService_DeleteCacheEntry_FullMethodName = "/ipquery.IPQueryService/DeleteCacheEntry"
+	IPQueryService_PurgeCache_FullMethodName       = "/ipquery.IPQueryService/PurgeCache"
+	IPQueryService_FlushCache_FullMethodName       = "/ipquery.IPQueryService/FlushCache"
 )
 
 // IPQueryServiceClient is the client API for IPQueryService service.
@@ -34,8 +41,22 @@ type IPQueryServiceClient interface {
 	QueryIP(ctx context.Context, in *QueryIPRequest, opts ...grpc.CallOption) (*QueryIPResponse, error)
 	// 批量查询IP地址信息
 	BatchQueryIP(ctx context.Context, in *BatchQueryIPRequest, opts ...grpc.CallOption) (*BatchQueryIPResponse, error)
+	// 查询网段内的子范围及汇总信息
+	QueryCIDR(ctx context.Context, in *QueryCIDRRequest, opts ...grpc.CallOption) (*QueryCIDRResponse, error)
+	// 按国家、省份、城市或ISP反查IP段，按地址顺序流式返回全部结果
+	FindRanges(ctx context.Context, in *FindRangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[IPInfo], error)
 	// 获取服务状态
 	GetServiceStatus(ctx context.Context, in *GetServiceStatusRequest, opts ...grpc.CallOption) (*GetServiceStatusResponse, error)
+	// 获取缓存统计及命中率
+	GetCacheStats(ctx context.Context, in *GetCacheStatsRequest, opts ...grpc.CallOption) (*GetCacheStatsResponse, error)
+	// 查看IP的缓存条目及其过期时间
+	InspectCache(ctx context.Context, in *InspectCacheRequest, opts ...grpc.CallOption) (*InspectCacheResponse, error)
+	// 删除IP的缓存条目
+	DeleteCacheEntry(ctx context.Context, in *DeleteCacheEntryRequest, opts ...grpc.CallOption) (*DeleteCacheEntryResponse, error)
+	// 删除网段内所有IP的缓存条目
+	PurgeCache(ctx context.Context, in *PurgeCacheRequest, opts ...grpc.CallOption) (*PurgeCacheResponse, error)
+	// 清空缓存
+	FlushCache(ctx context.Context, in *FlushCacheRequest, opts ...grpc.CallOption) (*FlushCacheResponse, error)
 }
 
 type iPQueryServiceClient struct {
@@ -66,6 +87,35 @@ func (c *iPQueryServiceClient) BatchQueryIP(ctx context.Context, in *BatchQueryI
 	return out, nil
 }
 
+func (c *iPQueryServiceClient) QueryCIDR(ctx context.Context, in *QueryCIDRRequest, opts ...grpc.CallOption) (*QueryCIDRResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryCIDRResponse)
+	err := c.cc.Invoke(ctx, IPQueryService_QueryCIDR_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iPQueryServiceClient) FindRanges(ctx context.Context, in *FindRangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[IPInfo], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &IPQueryService_ServiceDesc.Streams[0], IPQueryService_FindRanges_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[FindRangesRequest, IPInfo]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IPQueryService_FindRangesClient = grpc.ServerStreamingClient[IPInfo]
+
 func (c *iPQueryServiceClient) GetServiceStatus(ctx context.Context, in *GetServiceStatusRequest, opts ...grpc.CallOption) (*GetServiceStatusResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetServiceStatusResponse)
@@ -76,6 +126,56 @@ func (c *iPQueryServiceClient) GetServiceStatus(ctx context.Context, in *GetServ
 	return out, nil
 }
 
+func (c *iPQueryServiceClient) GetCacheStats(ctx context.Context, in *GetCacheStatsRequest, opts ...grpc.CallOption) (*GetCacheStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCacheStatsResponse)
+	err := c.cc.Invoke(ctx, IPQueryService_GetCacheStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iPQueryServiceClient) InspectCache(ctx context.Context, in *InspectCacheRequest, opts ...grpc.CallOption) (*InspectCacheResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InspectCacheResponse)
+	err := c.cc.Invoke(ctx, IPQueryService_InspectCache_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iPQueryServiceClient) DeleteCacheEntry(ctx context.Context, in *DeleteCacheEntryRequest, opts ...grpc.CallOption) (*DeleteCacheEntryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteCacheEntryResponse)
+	err := c.cc.Invoke(ctx, IPQueryService_DeleteCacheEntry_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iPQueryServiceClient) PurgeCache(ctx context.Context, in *PurgeCacheRequest, opts ...grpc.CallOption) (*PurgeCacheResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurgeCacheResponse)
+	err := c.cc.Invoke(ctx, IPQueryService_PurgeCache_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iPQueryServiceClient) FlushCache(ctx context.Context, in *FlushCacheRequest, opts ...grpc.CallOption) (*FlushCacheResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FlushCacheResponse)
+	err := c.cc.Invoke(ctx, IPQueryService_FlushCache_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // IPQueryServiceServer is the server API for IPQueryService service.
 // All implementations must embed UnimplementedIPQueryServiceServer
 // for forward compatibility.
@@ -86,8 +186,22 @@ type IPQueryServiceServer interface {
 	QueryIP(context.Context, *QueryIPRequest) (*QueryIPResponse, error)
 	// 批量查询IP地址信息
 	BatchQueryIP(context.Context, *BatchQueryIPRequest) (*BatchQueryIPResponse, error)
+	// 查询网段内的子范围及汇总信息
+	QueryCIDR(context.Context, *QueryCIDRRequest) (*QueryCIDRResponse, error)
+	// 按国家、省份、城市或ISP反查IP段，按地址顺序流式返回全部结果
+	FindRanges(*FindRangesRequest, grpc.ServerStreamingServer[IPInfo]) error
 	// 获取服务状态
 	GetServiceStatus(context.Context, *GetServiceStatusRequest) (*GetServiceStatusResponse, error)
+	// 获取缓存统计及命中率
+	GetCacheStats(context.Context, *GetCacheStatsRequest) (*GetCacheStatsResponse, error)
+	// 查看IP的缓存条目及其过期时间
+	InspectCache(context.Context, *InspectCacheRequest) (*InspectCacheResponse, error)
+	// 删除IP的缓存条目
+	DeleteCacheEntry(context.Context, *DeleteCacheEntryRequest) (*DeleteCacheEntryResponse, error)
+	// 删除网段内所有IP的缓存条目
+	PurgeCache(context.Context, *PurgeCacheRequest) (*PurgeCacheResponse, error)
+	// 清空缓存
+	FlushCache(context.Context, *FlushCacheRequest) (*FlushCacheResponse, error)
 	mustEmbedUnimplementedIPQueryServiceServer()
 }
 
@@ -104,9 +218,30 @@ func (UnimplementedIPQueryServiceServer) QueryIP(context.Context, *QueryIPReques
 func (UnimplementedIPQueryServiceServer) BatchQueryIP(context.Context, *BatchQueryIPRequest) (*BatchQueryIPResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BatchQueryIP not implemented")
 }
+func (UnimplementedIPQueryServiceServer) QueryCIDR(context.Context, *QueryCIDRRequest) (*QueryCIDRResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryCIDR not implemented")
+}
+func (UnimplementedIPQueryServiceServer) FindRanges(*FindRangesRequest, grpc.ServerStreamingServer[IPInfo]) error {
+	return status.Errorf(codes.Unimplemented, "method FindRanges not implemented")
+}
 func (UnimplementedIPQueryServiceServer) GetServiceStatus(context.Context, *GetServiceStatusRequest) (*GetServiceStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetServiceStatus not implemented")
 }
+func (UnimplementedIPQueryServiceServer) GetCacheStats(context.Context, *GetCacheStatsRequest) (*GetCacheStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCacheStats not implemented")
+}
+func (UnimplementedIPQueryServiceServer) InspectCache(context.Context, *InspectCacheRequest) (*InspectCacheResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InspectCache not implemented")
+}
+func (UnimplementedIPQueryServiceServer) DeleteCacheEntry(context.Context, *DeleteCacheEntryRequest) (*DeleteCacheEntryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCacheEntry not implemented")
+}
+func (UnimplementedIPQueryServiceServer) PurgeCache(context.Context, *PurgeCacheRequest) (*PurgeCacheResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeCache not implemented")
+}
+func (UnimplementedIPQueryServiceServer) FlushCache(context.Context, *FlushCacheRequest) (*FlushCacheResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FlushCache not implemented")
+}
 func (UnimplementedIPQueryServiceServer) mustEmbedUnimplementedIPQueryServiceServer() {}
 func (UnimplementedIPQueryServiceServer) testEmbeddedByValue()                        {}
 
@@ -164,6 +299,35 @@ func _IPQueryService_BatchQueryIP_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _IPQueryService_QueryCIDR_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryCIDRRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPQueryServiceServer).QueryCIDR(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPQueryService_QueryCIDR_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPQueryServiceServer).QueryCIDR(ctx, req.(*QueryCIDRRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IPQueryService_FindRanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FindRangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IPQueryServiceServer).FindRanges(m, &grpc.GenericServerStream[FindRangesRequest, IPInfo]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IPQueryService_FindRangesServer = grpc.ServerStreamingServer[IPInfo]
+
 func _IPQueryService_GetServiceStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetServiceStatusRequest)
 	if err := dec(in); err != nil {
@@ -182,6 +346,96 @@ func _IPQueryService_GetServiceStatus_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _IPQueryService_GetCacheStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCacheStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPQueryServiceServer).GetCacheStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPQueryService_GetCacheStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPQueryServiceServer).GetCacheStats(ctx, req.(*GetCacheStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IPQueryService_InspectCache_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InspectCacheRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPQueryServiceServer).InspectCache(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPQueryService_InspectCache_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPQueryServiceServer).InspectCache(ctx, req.(*InspectCacheRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IPQueryService_DeleteCacheEntry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCacheEntryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPQueryServiceServer).DeleteCacheEntry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPQueryService_DeleteCacheEntry_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPQueryServiceServer).DeleteCacheEntry(ctx, req.(*DeleteCacheEntryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IPQueryService_PurgeCache_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeCacheRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPQueryServiceServer).PurgeCache(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPQueryService_PurgeCache_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPQueryServiceServer).PurgeCache(ctx, req.(*PurgeCacheRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IPQueryService_FlushCache_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FlushCacheRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IPQueryServiceServer).FlushCache(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IPQueryService_FlushCache_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IPQueryServiceServer).FlushCache(ctx, req.(*FlushCacheRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // IPQueryService_ServiceDesc is the grpc.ServiceDesc for IPQueryService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -197,11 +451,41 @@ var IPQueryService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "BatchQueryIP",
 			Handler:    _IPQueryService_BatchQueryIP_Handler,
 		},
+		{
+			MethodName: "QueryCIDR",
+			Handler:    _IPQueryService_QueryCIDR_Handler,
+		},
 		{
 			MethodName: "GetServiceStatus",
 			Handler:    _IPQueryService_GetServiceStatus_Handler,
 		},
+		{
+			MethodName: "GetCacheStats",
+			Handler:    _IPQueryService_GetCacheStats_Handler,
+		},
+		{
+			MethodName: "InspectCache",
+			Handler:    _IPQueryService_InspectCache_Handler,
+		},
+		{
+			MethodName: "DeleteCacheEntry",
+			Handler:    _IPQueryService_DeleteCacheEntry_Handler,
+		},
+		{
+			MethodName: "PurgeCache",
+			Handler:    _IPQueryService_PurgeCache_Handler,
+		},
+		{
+			MethodName: "FlushCache",
+			Handler:    _IPQueryService_FlushCache_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "FindRanges",
+			Handler:       _IPQueryService_FindRanges_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/proto/ipquery.proto",
 }
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/ushell/goip/internal/ipquery"
+)
+
+// runExport 按条件反查IP段并生成防火墙或代理配置
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	configPath := fs.String("config", "./configs", "配置文件目录")
+	filter := addFilterFlags(fs)
+	format := fs.String("format", ipquery.ExportFormatIPSet, "导出格式: "+strings.Join(ipquery.ExportFormats(), ", "))
+	var opts ipquery.ExportOptions
+	fs.StringVar(&opts.Name, "name", "", "ipset/nftables集合名、iptables链名或nginx变量名 (默认goip)")
+	fs.StringVar(&opts.Value, "value", "", "nginx geo和HAProxy map中命中时的值 (默认1)")
+	fs.StringVar(&opts.Target, "target", "", "iptables规则的动作 (默认DROP)")
+	fs.StringVar(&opts.Table, "table", "", "nftables表名 (默认goip)")
+	fs.IntVar(&opts.Family, "family", 0, "只导出IPv4(4)或IPv6(6)，iptables默认为4")
+	fs.Parse(args)
+
+	if filter.IsEmpty() {
+		return errEmptyFilter
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	exporter, err := ipquery.NewExporter(*format, w, opts)
+	if err != nil {
+		return err
+	}
+
+	provider, err := openProvider(*configPath)
+	if err != nil {
+		return err
+	}
+	defer provider.Close()
+
+	if err := ipquery.ExportRanges(provider, *filter, exporter); err != nil {
+		return err
+	}
+	return w.Flush()
+}
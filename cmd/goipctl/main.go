@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/ushell/goip/internal/config"
+	"github.com/ushell/goip/internal/ipquery"
+	"github.com/ushell/goip/internal/service"
+)
+
+// command 子命令
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+// commands 所有子命令
+var commands = map[string]command{
+	"ranges": {usage: "按国家、省份、城市或ISP导出IP段", run: runRanges},
+	"export": {usage: "生成ipset、nftables、iptables、nginx geo或HAProxy map配置", run: runExport},
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "未知命令: %s\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(flag.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+}
+
+// usage 输出帮助信息
+func usage() {
+	fmt.Fprintln(os.Stderr, "用法: goipctl <命令> [参数]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "命令:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].usage)
+	}
+
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "使用 goipctl <命令> -h 查看命令参数")
+}
+
+// openProvider 加载配置并直接打开IP数据库，不启动服务
+func openProvider(configPath string) (ipquery.QueryProvider, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("加载配置失败: %w", err)
+	}
+
+	provider, err := ipquery.NewProvider(service.DatabaseOptions(cfg.IPDatabase))
+	if err != nil {
+		return nil, fmt.Errorf("打开IP数据库失败: %w", err)
+	}
+	return provider, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/netip"
+	"os"
+
+	"github.com/ushell/goip/internal/ipquery"
+	"github.com/ushell/goip/pkg/i18n"
+)
+
+// errEmptyFilter 未指定反查条件
+var errEmptyFilter = errors.New("至少需要指定 -country、-region、-city 或 -isp 中的一个")
+
+// addFilterFlags 注册反查条件参数
+func addFilterFlags(fs *flag.FlagSet) *ipquery.RangeFilter {
+	filter := &ipquery.RangeFilter{}
+	fs.StringVar(&filter.Country, "country", "", "国家名称或ISO代码")
+	fs.StringVar(&filter.Region, "region", "", "省份/州")
+	fs.StringVar(&filter.City, "city", "", "城市")
+	fs.StringVar(&filter.ISP, "isp", "", "ISP")
+	return filter
+}
+
+// runRanges 按条件反查IP段并输出到标准输出
+func runRanges(args []string) error {
+	fs := flag.NewFlagSet("ranges", flag.ExitOnError)
+	configPath := fs.String("config", "./configs", "配置文件目录")
+	filter := addFilterFlags(fs)
+	format := fs.String("format", "cidr", "输出格式: cidr(合并后的CIDR列表), ndjson(每行一个IP段的JSON)")
+	lang := fs.String("lang", "", "ndjson输出的语言: zh-CN(默认), en")
+	fs.Parse(args)
+
+	if filter.IsEmpty() {
+		return errEmptyFilter
+	}
+	if *format != "cidr" && *format != "ndjson" {
+		return fmt.Errorf("不支持的输出格式: %s", *format)
+	}
+
+	provider, err := openProvider(*configPath)
+	if err != nil {
+		return err
+	}
+	defer provider.Close()
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	if *format == "ndjson" {
+		outputLang := i18n.Resolve(*lang, "")
+		encoder := json.NewEncoder(w)
+		return ipquery.FindRanges(provider, *filter, func(start, end netip.Addr, info *ipquery.IPInfo) error {
+			return encoder.Encode(info.Localize(outputLang))
+		})
+	}
+
+	merger := ipquery.NewRangeMerger(func(prefix netip.Prefix) error {
+		_, err := fmt.Fprintln(w, prefix)
+		return err
+	})
+	err = ipquery.FindRanges(provider, *filter, func(start, end netip.Addr, info *ipquery.IPInfo) error {
+		return merger.Add(start, end)
+	})
+	if err != nil {
+		return err
+	}
+	return merger.Flush()
+}
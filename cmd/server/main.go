@@ -19,6 +19,13 @@ import (
 	"google.golang.org/grpc"
 )
 
+// 构建信息，由Makefile通过-ldflags注入
+var (
+	Version   = "dev"
+	BuildTime = "unknown"
+	GitCommit = "unknown"
+)
+
 func main() {
 	// 加载配置
 	cfg, err := config.Load("./configs")
@@ -37,6 +44,11 @@ func main() {
 		log.WithError(err).Fatal("创建IP服务失败")
 	}
 	defer ipService.Close()
+	ipService.SetBuildInfo(service.BuildInfo{
+		Version:   Version,
+		BuildTime: BuildTime,
+		GitCommit: GitCommit,
+	})
 
 	// 创建HTTP服务器
 	httpHandler := handler.NewHTTPHandler(ipService, log)
@@ -60,8 +72,12 @@ func main() {
 	}()
 
 	// 启动gRPC服务器（暂时注释掉，等待proto文件生成）
-	grpcServer := grpc.NewServer()
-	pb.RegisterIPQueryServiceServer(grpcServer, handler.NewGRPCServer(ipService, log))
+	grpcHandler := handler.NewGRPCServer(ipService, log)
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(grpcHandler.UnaryInterceptor()),
+		grpc.StreamInterceptor(grpcHandler.StreamInterceptor()),
+	)
+	pb.RegisterIPQueryServiceServer(grpcServer, grpcHandler)
 
 	lis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", cfg.Server.GRPC.Host, cfg.Server.GRPC.Port))
 	if err != nil {
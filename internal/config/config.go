@@ -11,7 +11,12 @@ type Config struct {
 	Server      ServerConfig      `mapstructure:"server"`
 	Logging     LoggingConfig     `mapstructure:"logging"`
 	IPDatabase  IPDatabaseConfig  `mapstructure:"ip_database"`
+	Overrides   OverridesConfig   `mapstructure:"overrides"`
+	ASN         ASNConfig         `mapstructure:"asn"`
+	Gazetteer   GazetteerConfig   `mapstructure:"gazetteer"`
+	Reputation  ReputationConfig  `mapstructure:"reputation"`
 	Cache       CacheConfig       `mapstructure:"cache"`
+	Admin       AdminConfig       `mapstructure:"admin"`
 	Metrics     MetricsConfig     `mapstructure:"metrics"`
 	HealthCheck HealthCheckConfig `mapstructure:"health_check"`
 }
@@ -49,19 +54,132 @@ type LoggingConfig struct {
 
 // IPDatabaseConfig IP数据库配置
 type IPDatabaseConfig struct {
-	Type           string        `mapstructure:"type"`
+	Type           string           `mapstructure:"type"`
+	Path           string           `mapstructure:"path"`
+	IPv6Path       string           `mapstructure:"ipv6_path"`
+	CacheSize      int              `mapstructure:"cache_size"`
+	LoadMode       string           `mapstructure:"load_mode"`
+	PoolSize       int              `mapstructure:"pool_size"` // 已废弃，xdb查询不再使用查询器池
+	AutoReload     bool             `mapstructure:"auto_reload"`
+	ReloadInterval time.Duration    `mapstructure:"reload_interval"`
+	MMDB           MMDBConfig       `mapstructure:"mmdb"`
+	Composite      CompositeConfig  `mapstructure:"composite"`
+	Remote         RemoteConfig     `mapstructure:"remote"`
+	Validation     ValidationConfig `mapstructure:"validation"`
+}
+
+// ValidationConfig 数据库校验配置，启动和每次重新加载时新数据库必须通过校验才会启用
+type ValidationConfig struct {
+	Enabled      bool               `mapstructure:"enabled"`
+	Samples      int                `mapstructure:"samples"`
+	Completeness map[string]float64 `mapstructure:"completeness"`
+	Canaries     []CanaryConfig     `mapstructure:"canaries"`
+}
+
+// CanaryConfig 哨兵IP及其期望结果，为空的字段不检查
+type CanaryConfig struct {
+	IP          string `mapstructure:"ip"`
+	Country     string `mapstructure:"country"`
+	CountryCode string `mapstructure:"country_code"`
+	Region      string `mapstructure:"region"`
+	City        string `mapstructure:"city"`
+	ISP         string `mapstructure:"isp"`
+}
+
+// RemoteConfig 远程数据库下载配置，type为remote时使用
+type RemoteConfig struct {
+	URL          string        `mapstructure:"url"`
+	Format       string        `mapstructure:"format"`
+	Interval     time.Duration `mapstructure:"interval"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	SHA256       string        `mapstructure:"sha256"`
+	ChecksumURL  string        `mapstructure:"checksum_url"`
+	PublicKey    string        `mapstructure:"public_key"`
+	SignatureURL string        `mapstructure:"signature_url"`
+	Insecure     bool          `mapstructure:"insecure"` // 未配置sha256、checksum_url和public_key时必须显式设置为true
+}
+
+// MMDBConfig MaxMind MMDB数据库配置
+type MMDBConfig struct {
+	CityPath    string `mapstructure:"city_path"`
+	CountryPath string `mapstructure:"country_path"`
+	ASNPath     string `mapstructure:"asn_path"`
+	Language    string `mapstructure:"language"`
+}
+
+// CompositeConfig 组合提供者配置
+type CompositeConfig struct {
+	Providers  []CompositeMemberConfig `mapstructure:"providers"`
+	Precedence map[string][]string     `mapstructure:"precedence"`
+}
+
+// CompositeMemberConfig 组合提供者成员配置
+type CompositeMemberConfig struct {
+	Type    string        `mapstructure:"type"`
+	Timeout time.Duration `mapstructure:"timeout"`
+	Fields  []string      `mapstructure:"fields"`
+}
+
+// OverridesConfig CIDR覆盖表配置
+type OverridesConfig struct {
+	Path           string        `mapstructure:"path"`
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+}
+
+// ASNConfig ASN数据库配置
+type ASNConfig struct {
 	Path           string        `mapstructure:"path"`
-	CacheSize      int           `mapstructure:"cache_size"`
-	AutoReload     bool          `mapstructure:"auto_reload"`
 	ReloadInterval time.Duration `mapstructure:"reload_interval"`
 }
 
+// GazetteerConfig 离线地名库配置
+type GazetteerConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	Path           string        `mapstructure:"path"`
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+}
+
+// ReputationConfig IP信誉列表配置
+type ReputationConfig struct {
+	RefreshInterval time.Duration          `mapstructure:"refresh_interval"`
+	Lists           []ReputationListConfig `mapstructure:"lists"`
+}
+
+// ReputationListConfig 单个信誉列表配置
+type ReputationListConfig struct {
+	Name     string `mapstructure:"name"`
+	Category string `mapstructure:"category"`
+	Path     string `mapstructure:"path"`
+}
+
 // CacheConfig 缓存配置
 type CacheConfig struct {
-	Enabled bool          `mapstructure:"enabled"`
-	Type    string        `mapstructure:"type"`
-	TTL     time.Duration `mapstructure:"ttl"`
-	MaxSize int           `mapstructure:"max_size"`
+	Enabled  bool          `mapstructure:"enabled"`
+	Type     string        `mapstructure:"type"` // memory, redis, tiered
+	TTL      time.Duration `mapstructure:"ttl"`
+	LocalTTL time.Duration `mapstructure:"local_ttl"` // type为tiered时本地内存缓存的有效期
+	MaxSize  int           `mapstructure:"max_size"`
+	MaxBytes int64         `mapstructure:"max_bytes"` // 估算内存上限(字节)，0表示不限制
+	Policy   string        `mapstructure:"policy"`    // lru, lfu, tinylfu
+	Redis    RedisConfig   `mapstructure:"redis"`     // type为redis或tiered时使用
+}
+
+// RedisConfig Redis缓存配置
+type RedisConfig struct {
+	Addr          string        `mapstructure:"addr"`
+	Username      string        `mapstructure:"username"`
+	Password      string        `mapstructure:"password"`
+	DB            int           `mapstructure:"db"`
+	PoolSize      int           `mapstructure:"pool_size"`
+	Prefix        string        `mapstructure:"prefix"`         // 键前缀，默认goip:
+	Timeout       time.Duration `mapstructure:"timeout"`        // 单次操作超时，默认100ms
+	RetryInterval time.Duration `mapstructure:"retry_interval"` // Redis出错后暂停访问的时间，默认5s
+}
+
+// AdminConfig 管理接口配置
+type AdminConfig struct {
+	// Token 调用管理接口需要携带的令牌(Authorization: Bearer <token>)，为空时不提供管理接口
+	Token string `mapstructure:"token"`
 }
 
 // MetricsConfig 监控配置
@@ -93,7 +211,15 @@ func Load(configPath string) (*Config, error) {
 	viper.SetDefault("server.grpc.port", 50051)
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("ip_database.type", "local")
+	viper.SetDefault("ip_database.load_mode", "auto")
+	viper.SetDefault("ip_database.validation.enabled", true)
+	viper.SetDefault("gazetteer.enabled", true)
 	viper.SetDefault("cache.enabled", true)
+	viper.SetDefault("cache.ttl", "1h")
+	viper.SetDefault("cache.max_size", 10000)
+	viper.SetDefault("cache.type", "memory")
+	viper.SetDefault("cache.local_ttl", "1m")
+	viper.SetDefault("cache.policy", "lru")
 	viper.SetDefault("metrics.enabled", true)
 	viper.SetDefault("health_check.enabled", true)
 
@@ -2,12 +2,19 @@ package handler
 
 import (
 	"context"
+	"net/netip"
+	"strings"
 	"time"
 
 	pb "github.com/ushell/goip/api/proto"
 	"github.com/ushell/goip/internal/ipquery"
 	"github.com/ushell/goip/internal/service"
+	"github.com/ushell/goip/pkg/errors"
+	"github.com/ushell/goip/pkg/i18n"
 	"github.com/ushell/goip/pkg/logger"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 // GRPCServer gRPC服务器
@@ -28,6 +35,7 @@ func NewGRPCServer(service *service.IPService, logger *logger.Logger) *GRPCServe
 // QueryIP 查询单个IP地址信息
 func (s *GRPCServer) QueryIP(ctx context.Context, req *pb.QueryIPRequest) (*pb.QueryIPResponse, error) {
 	s.logger.WithField("ip", req.Ip).Debug("收到gRPC查询IP请求")
+	lang := requestLangFromContext(ctx, req.Lang)
 
 	info, err := s.service.QueryIP(req.Ip)
 	if err != nil {
@@ -36,14 +44,14 @@ func (s *GRPCServer) QueryIP(ctx context.Context, req *pb.QueryIPRequest) (*pb.Q
 			Info: &pb.IPInfo{
 				Ip:           req.Ip,
 				IsValid:      false,
-				ErrorMessage: err.Error(),
+				ErrorMessage: errors.GetLocalizedMessage(err, lang),
 			},
 			Timestamp: time.Now().Unix(),
 		}, nil
 	}
 
 	return &pb.QueryIPResponse{
-		Info:      convertToProtoIPInfo(info),
+		Info:      convertToProtoIPInfo(info.Localize(lang)),
 		Timestamp: time.Now().Unix(),
 	}, nil
 }
@@ -51,6 +59,7 @@ func (s *GRPCServer) QueryIP(ctx context.Context, req *pb.QueryIPRequest) (*pb.Q
 // BatchQueryIP 批量查询IP地址信息
 func (s *GRPCServer) BatchQueryIP(ctx context.Context, req *pb.BatchQueryIPRequest) (*pb.BatchQueryIPResponse, error) {
 	s.logger.WithField("count", len(req.Ips)).Debug("收到gRPC批量查询IP请求")
+	lang := requestLangFromContext(ctx, req.Lang)
 
 	infos, err := s.service.BatchQueryIP(req.Ips)
 	if err != nil {
@@ -63,7 +72,7 @@ func (s *GRPCServer) BatchQueryIP(ctx context.Context, req *pb.BatchQueryIPReque
 
 	protoInfos := make([]*pb.IPInfo, 0, len(infos))
 	for _, info := range infos {
-		protoInfos = append(protoInfos, convertToProtoIPInfo(info))
+		protoInfos = append(protoInfos, convertToProtoIPInfo(info.Localize(lang)))
 	}
 
 	return &pb.BatchQueryIPResponse{
@@ -72,33 +81,337 @@ func (s *GRPCServer) BatchQueryIP(ctx context.Context, req *pb.BatchQueryIPReque
 	}, nil
 }
 
+// statusError 按错误码将服务错误转换为gRPC状态，请求错误返回InvalidArgument，其余返回Internal
+func statusError(err error, lang i18n.Lang) error {
+	code := codes.Internal
+	switch errors.GetCode(err) {
+	case errors.ErrCodeInvalidIP, errors.ErrCodeInvalidRequest:
+		code = codes.InvalidArgument
+	}
+	return status.Error(code, errors.GetLocalizedMessage(err, lang))
+}
+
+// QueryCIDR 查询网段内的子范围及汇总信息
+func (s *GRPCServer) QueryCIDR(ctx context.Context, req *pb.QueryCIDRRequest) (*pb.QueryCIDRResponse, error) {
+	s.logger.WithField("prefix", req.Prefix).Debug("收到gRPC查询网段请求")
+	lang := requestLangFromContext(ctx, req.Lang)
+
+	report, err := s.service.QueryCIDR(req.Prefix, int(req.Limit))
+	if err != nil {
+		s.logger.WithError(err).WithField("prefix", req.Prefix).Error("查询网段失败")
+		return nil, statusError(err, lang)
+	}
+	report = report.Localize(lang)
+
+	ranges := make([]*pb.IPInfo, 0, len(report.Ranges))
+	for _, info := range report.Ranges {
+		ranges = append(ranges, convertToProtoIPInfo(info))
+	}
+
+	return &pb.QueryCIDRResponse{
+		Prefix:      report.Prefix,
+		Ranges:      ranges,
+		TotalRanges: int32(report.TotalRanges),
+		Truncated:   report.Truncated,
+		Countries:   convertToProtoSummary(report.Countries),
+		Isps:        convertToProtoSummary(report.ISPs),
+		Timestamp:   time.Now().Unix(),
+	}, nil
+}
+
+// FindRanges 按国家、省份、城市或ISP反查IP段，流式返回全部结果
+func (s *GRPCServer) FindRanges(req *pb.FindRangesRequest, stream pb.IPQueryService_FindRangesServer) error {
+	s.logger.WithField("country", req.Country).WithField("region", req.Region).
+		WithField("city", req.City).WithField("isp", req.Isp).Debug("收到gRPC反查IP段请求")
+	lang := requestLangFromContext(stream.Context(), req.Lang)
+
+	filter := ipquery.RangeFilter{
+		Country: req.Country,
+		Region:  req.Region,
+		City:    req.City,
+		ISP:     req.Isp,
+	}
+	err := s.service.StreamRanges(filter, func(start, end netip.Addr, info *ipquery.IPInfo) error {
+		return stream.Send(convertToProtoIPInfo(info.Localize(lang)))
+	})
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		s.logger.WithError(err).Error("反查IP段失败")
+		return statusError(err, lang)
+	}
+	return nil
+}
+
 // GetServiceStatus 获取服务状态
 func (s *GRPCServer) GetServiceStatus(ctx context.Context, req *pb.GetServiceStatusRequest) (*pb.GetServiceStatusResponse, error) {
 	status := s.service.GetServiceStatus()
 
 	return &pb.GetServiceStatusResponse{
-		Status:     status["status"].(string),
-		Version:    status["version"].(string),
-		Uptime:     int64(status["uptime"].(float64)),
-		QueryCount: status["query_count"].(int64),
+		Status:         status["status"].(string),
+		Version:        status["version"].(string),
+		Uptime:         int64(status["uptime"].(float64)),
+		QueryCount:     status["query_count"].(int64),
+		BuildTime:      status["build_time"].(string),
+		GitCommit:      status["git_commit"].(string),
+		Database:       convertToProtoDatabaseInfo(status["database"].(*ipquery.DatabaseInfo)),
+		CollapsedCount: status["collapsed_count"].(int64),
+	}, nil
+}
+
+// convertToProtoDatabaseInfo 转换为protobuf DatabaseInfo
+func convertToProtoDatabaseInfo(info *ipquery.DatabaseInfo) *pb.DatabaseInfo {
+	if info == nil {
+		return nil
+	}
+
+	files := make([]*pb.DatabaseFile, 0, len(info.Files))
+	for _, f := range info.Files {
+		file := &pb.DatabaseFile{
+			Path:    f.Path,
+			Format:  f.Format,
+			Version: f.Version,
+			Records: int64(f.Records),
+			Size:    f.Size,
+			Sha256:  f.SHA256,
+		}
+		if f.BuildTime != nil {
+			file.BuildTime = f.BuildTime.Unix()
+		}
+		files = append(files, file)
+	}
+
+	return &pb.DatabaseInfo{
+		Type:     info.Type,
+		Files:    files,
+		LoadedAt: info.LoadedAt.Unix(),
+	}
+}
+
+// authorizeAdmin 校验管理接口令牌，令牌通过authorization元数据传递(Bearer <token>)
+// 未配置admin.token时返回PermissionDenied，令牌错误时返回Unauthenticated
+func (s *GRPCServer) authorizeAdmin(ctx context.Context, lang i18n.Lang) error {
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token = strings.TrimPrefix(values[0], "Bearer ")
+		}
+	}
+	if err := s.service.AuthorizeAdmin(token); err != nil {
+		s.logger.Warn("管理接口未授权")
+		code := codes.Unauthenticated
+		if errors.Is(err, errors.ErrCodeForbidden) {
+			code = codes.PermissionDenied
+		}
+		return status.Error(code, errors.GetLocalizedMessage(err, lang))
+	}
+	return nil
+}
+
+// cacheStatusError 将缓存管理错误转换为gRPC状态
+// 缓存未启用时返回FailedPrecondition，缓存后端不可用时返回Unavailable
+func cacheStatusError(err error, lang i18n.Lang) error {
+	code := codes.InvalidArgument
+	switch errors.GetCode(err) {
+	case errors.ErrCodeCacheError:
+		code = codes.FailedPrecondition
+	case errors.ErrCodeCacheUnavailable:
+		code = codes.Unavailable
+	}
+	return status.Error(code, errors.GetLocalizedMessage(err, lang))
+}
+
+// GetCacheStats 获取缓存统计及命中率
+func (s *GRPCServer) GetCacheStats(ctx context.Context, req *pb.GetCacheStatsRequest) (*pb.GetCacheStatsResponse, error) {
+	lang := requestLangFromContext(ctx, "")
+	if err := s.authorizeAdmin(ctx, lang); err != nil {
+		return nil, err
+	}
+
+	report, err := s.service.CacheStats()
+	if err != nil {
+		return nil, cacheStatusError(err, lang)
+	}
+
+	return &pb.GetCacheStatsResponse{
+		Stats:          convertToProtoCacheStats(report.CacheStats),
+		HitRate:        report.HitRate,
+		MissRate:       report.MissRate,
+		EvictionRate:   report.EvictionRate,
+		CollapsedCount: report.CollapsedCount,
 	}, nil
 }
 
+// InspectCache 查看IP的缓存条目及其过期时间
+func (s *GRPCServer) InspectCache(ctx context.Context, req *pb.InspectCacheRequest) (*pb.InspectCacheResponse, error) {
+	lang := requestLangFromContext(ctx, req.Lang)
+	if err := s.authorizeAdmin(ctx, lang); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.service.InspectCache(req.Ip)
+	if err != nil {
+		return nil, cacheStatusError(err, lang)
+	}
+
+	resp := &pb.InspectCacheResponse{
+		Entries: make([]*pb.CachedEntry, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		cached := &pb.CachedEntry{
+			Key:   entry.Key,
+			Cache: entry.Cache,
+			Info:  convertToProtoIPInfo(entry.Value.Localize(lang)),
+		}
+		if entry.ExpiresAt != nil {
+			cached.ExpiresAt = entry.ExpiresAt.Unix()
+		}
+		resp.Entries = append(resp.Entries, cached)
+	}
+	return resp, nil
+}
+
+// DeleteCacheEntry 删除IP的缓存条目
+func (s *GRPCServer) DeleteCacheEntry(ctx context.Context, req *pb.DeleteCacheEntryRequest) (*pb.DeleteCacheEntryResponse, error) {
+	lang := requestLangFromContext(ctx, "")
+	if err := s.authorizeAdmin(ctx, lang); err != nil {
+		return nil, err
+	}
+
+	if err := s.service.DeleteCacheEntry(req.Ip); err != nil {
+		return nil, cacheStatusError(err, lang)
+	}
+	return &pb.DeleteCacheEntryResponse{}, nil
+}
+
+// PurgeCache 删除网段内所有IP的缓存条目
+func (s *GRPCServer) PurgeCache(ctx context.Context, req *pb.PurgeCacheRequest) (*pb.PurgeCacheResponse, error) {
+	lang := requestLangFromContext(ctx, "")
+	if err := s.authorizeAdmin(ctx, lang); err != nil {
+		return nil, err
+	}
+
+	purged, err := s.service.PurgeCache(req.Prefix)
+	if err != nil {
+		return nil, cacheStatusError(err, lang)
+	}
+	return &pb.PurgeCacheResponse{Deleted: int64(purged)}, nil
+}
+
+// FlushCache 清空缓存
+func (s *GRPCServer) FlushCache(ctx context.Context, req *pb.FlushCacheRequest) (*pb.FlushCacheResponse, error) {
+	lang := requestLangFromContext(ctx, "")
+	if err := s.authorizeAdmin(ctx, lang); err != nil {
+		return nil, err
+	}
+
+	if err := s.service.FlushCache(); err != nil {
+		return nil, cacheStatusError(err, lang)
+	}
+	return &pb.FlushCacheResponse{}, nil
+}
+
+// convertToProtoCacheStats 转换为protobuf CacheStats
+func convertToProtoCacheStats(stats ipquery.CacheStats) *pb.CacheStats {
+	tiers := make([]*pb.CacheStats, 0, len(stats.Tiers))
+	for _, tier := range stats.Tiers {
+		tiers = append(tiers, convertToProtoCacheStats(tier))
+	}
+
+	return &pb.CacheStats{
+		Type:        stats.Type,
+		Policy:      string(stats.Policy),
+		Size:        int64(stats.Size),
+		Bytes:       stats.Bytes,
+		MaxSize:     int64(stats.MaxSize),
+		MaxBytes:    stats.MaxBytes,
+		Hits:        stats.Hits,
+		Misses:      stats.Misses,
+		Evictions:   stats.Evictions,
+		Expirations: stats.Expirations,
+		Rejections:  stats.Rejections,
+		Errors:      stats.Errors,
+		Tiers:       tiers,
+	}
+}
+
+// requestLangFromContext 根据请求中的lang字段或accept-language元数据确定响应语言
+func requestLangFromContext(ctx context.Context, lang string) i18n.Lang {
+	var acceptLanguage string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("accept-language"); len(values) > 0 {
+			acceptLanguage = values[0]
+		}
+	}
+	return i18n.Resolve(lang, acceptLanguage)
+}
+
 // convertToProtoIPInfo 转换为protobuf IPInfo
 func convertToProtoIPInfo(info *ipquery.IPInfo) *pb.IPInfo {
 	return &pb.IPInfo{
-		Ip:           info.IP,
-		Country:      info.Country,
-		CountryCode:  info.CountryCode,
-		Region:       info.Region,
-		City:         info.City,
-		District:     info.District,
-		Isp:          info.ISP,
-		Latitude:     float32(info.Latitude),
-		Longitude:    float32(info.Longitude),
-		Timezone:     info.Timezone,
-		PostalCode:   info.PostalCode,
-		IsValid:      info.IsValid,
-		ErrorMessage: info.ErrorMessage,
+		Ip:             info.IP,
+		Country:        info.Country,
+		CountryCode:    info.CountryCode,
+		Region:         info.Region,
+		City:           info.City,
+		District:       info.District,
+		Isp:            info.ISP,
+		Latitude:       float32(info.Latitude),
+		Longitude:      float32(info.Longitude),
+		Timezone:       info.Timezone,
+		PostalCode:     info.PostalCode,
+		IsValid:        info.IsValid,
+		ErrorMessage:   info.ErrorMessage,
+		Range:          convertToProtoIPRange(info.Range),
+		AddressType:    string(info.AddressType),
+		Scope:          info.Scope,
+		Tags:           info.Tags,
+		Asn:            info.ASN,
+		AsOrganization: info.ASOrganization,
+		AsPrefix:       info.ASPrefix,
+		AdminCode:      info.AdminCode,
+		Threat:         convertToProtoThreatInfo(info.Threat),
+	}
+}
+
+// convertToProtoThreatInfo 转换IP信誉信息为protobuf格式
+func convertToProtoThreatInfo(t *ipquery.ThreatInfo) *pb.ThreatInfo {
+	if t == nil {
+		return nil
+	}
+	return &pb.ThreatInfo{
+		IsHosting: t.IsHosting,
+		IsVpn:     t.IsVPN,
+		IsProxy:   t.IsProxy,
+		IsTor:     t.IsTor,
+		UsageType: t.UsageType,
+		Sources:   t.Sources,
+	}
+}
+
+// convertToProtoSummary 转换网段汇总为protobuf格式
+func convertToProtoSummary(items []ipquery.CIDRSummaryItem) []*pb.CIDRSummaryItem {
+	result := make([]*pb.CIDRSummaryItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, &pb.CIDRSummaryItem{
+			Name:   item.Name,
+			Code:   item.Code,
+			Ranges: int32(item.Ranges),
+			Share:  item.Share,
+		})
+	}
+	return result
+}
+
+// convertToProtoIPRange 转换IP范围为protobuf格式
+func convertToProtoIPRange(r *ipquery.IPRange) *pb.IPRange {
+	if r == nil {
+		return nil
+	}
+	return &pb.IPRange{
+		Start: r.Start,
+		End:   r.End,
+		Cidrs: r.CIDRs,
 	}
 }
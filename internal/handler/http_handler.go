@@ -1,13 +1,19 @@
 package handler
 
 import (
+	"bufio"
+	"encoding/json"
 	"net/http"
+	"net/netip"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ushell/goip/internal/ipquery"
 	"github.com/ushell/goip/internal/service"
 	"github.com/ushell/goip/pkg/errors"
+	"github.com/ushell/goip/pkg/i18n"
 	"github.com/ushell/goip/pkg/logger"
 )
 
@@ -27,13 +33,14 @@ func NewHTTPHandler(service *service.IPService, logger *logger.Logger) *HTTPHand
 
 // QueryIP 查询单个IP地址信息
 func (h *HTTPHandler) QueryIP(c *gin.Context) {
+	lang := requestLang(c)
 	ip := c.Param("ip")
 	ip = strings.TrimSpace(ip)
 
 	if ip == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    errors.ErrCodeInvalidRequest,
-			"message": "IP地址不能为空",
+			"message": i18n.Message("IP地址不能为空", lang),
 		})
 		return
 	}
@@ -43,19 +50,21 @@ func (h *HTTPHandler) QueryIP(c *gin.Context) {
 		h.logger.WithError(err).WithField("ip", ip).Error("查询IP失败")
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    errors.GetCode(err),
-			"message": errors.GetMessage(err),
+			"message": errors.GetLocalizedMessage(err, lang),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 0,
-		"data": info,
+		"data": info.Localize(lang),
 	})
 }
 
 // BatchQueryIP 批量查询IP地址信息
 func (h *HTTPHandler) BatchQueryIP(c *gin.Context) {
+	lang := requestLang(c)
+
 	var req struct {
 		IPs []string `json:"ips" binding:"required"`
 	}
@@ -63,7 +72,7 @@ func (h *HTTPHandler) BatchQueryIP(c *gin.Context) {
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    errors.ErrCodeInvalidRequest,
-			"message": "请求格式错误",
+			"message": i18n.Message("请求格式错误", lang),
 		})
 		return
 	}
@@ -73,14 +82,164 @@ func (h *HTTPHandler) BatchQueryIP(c *gin.Context) {
 		h.logger.WithError(err).Error("批量查询IP失败")
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    errors.GetCode(err),
-			"message": errors.GetMessage(err),
+			"message": errors.GetLocalizedMessage(err, lang),
+		})
+		return
+	}
+
+	localized := make([]*ipquery.IPInfo, 0, len(infos))
+	for _, info := range infos {
+		localized = append(localized, info.Localize(lang))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": localized,
+	})
+}
+
+// QueryCIDR 查询网段内的子范围及国家、ISP汇总
+func (h *HTTPHandler) QueryCIDR(c *gin.Context) {
+	lang := requestLang(c)
+	prefix := c.Param("addr") + "/" + c.Param("bits")
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	report, err := h.service.QueryCIDR(prefix, limit)
+	if err != nil {
+		h.logger.WithError(err).WithField("prefix", prefix).Error("查询网段失败")
+		c.JSON(statusCode(err), gin.H{
+			"code":    errors.GetCode(err),
+			"message": errors.GetLocalizedMessage(err, lang),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 0,
-		"data": infos,
+		"data": report.Localize(lang),
+	})
+}
+
+// 反查结果的输出格式
+const (
+	rangeFormatJSON   = "json"   // 分页JSON
+	rangeFormatCIDR   = "cidr"   // 合并相邻IP段后的CIDR列表，每行一个
+	rangeFormatNDJSON = "ndjson" // 每行一个IP段的JSON，流式输出全部结果
+)
+
+// FindRanges 按国家、省份、城市或ISP反查IP段
+func (h *HTTPHandler) FindRanges(c *gin.Context) {
+	lang := requestLang(c)
+	filter := rangeFilter(c)
+
+	format := c.DefaultQuery("format", rangeFormatJSON)
+	switch format {
+	case rangeFormatJSON:
+		offset, _ := strconv.Atoi(c.Query("offset"))
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		withTotal, _ := strconv.ParseBool(c.Query("total"))
+		page, err := h.service.FindRanges(filter, c.Query("after"), offset, limit, withTotal)
+		if err != nil {
+			h.rangeError(c, err, lang)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"code": 0,
+			"data": page.Localize(lang),
+		})
+
+	case rangeFormatCIDR:
+		c.Header("Content-Type", "text/plain; charset=utf-8")
+		w := bufio.NewWriter(c.Writer)
+		merger := ipquery.NewRangeMerger(func(prefix netip.Prefix) error {
+			_, err := w.WriteString(prefix.String() + "\n")
+			return err
+		})
+		err := h.service.StreamRanges(filter, func(start, end netip.Addr, info *ipquery.IPInfo) error {
+			return merger.Add(start, end)
+		})
+		if err == nil {
+			err = merger.Flush()
+		}
+		h.finishStream(c, w, err, lang)
+
+	case rangeFormatNDJSON:
+		c.Header("Content-Type", "application/x-ndjson")
+		w := bufio.NewWriter(c.Writer)
+		encoder := json.NewEncoder(w)
+		err := h.service.StreamRanges(filter, func(start, end netip.Addr, info *ipquery.IPInfo) error {
+			return encoder.Encode(info.Localize(lang))
+		})
+		h.finishStream(c, w, err, lang)
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidRequest,
+			"message": i18n.Message("不支持的输出格式", lang),
+		})
+	}
+}
+
+// ExportRanges 按国家、省份、城市或ISP导出ipset、nftables、iptables、nginx geo或HAProxy map配置
+func (h *HTTPHandler) ExportRanges(c *gin.Context) {
+	lang := requestLang(c)
+	filter := rangeFilter(c)
+	family, _ := strconv.Atoi(c.Query("family"))
+	opts := ipquery.ExportOptions{
+		Name:   c.Query("name"),
+		Value:  c.Query("value"),
+		Target: c.Query("target"),
+		Table:  c.Query("table"),
+		Family: family,
+	}
+
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	w := bufio.NewWriter(c.Writer)
+	err := h.service.ExportRanges(filter, c.Param("format"), opts, w)
+	h.finishStream(c, w, err, lang)
+}
+
+// finishStream 结束流式输出，响应尚未发出时丢弃缓冲内容并以JSON返回错误
+func (h *HTTPHandler) finishStream(c *gin.Context, w *bufio.Writer, err error, lang i18n.Lang) {
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		return
+	}
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Type")
+		h.rangeError(c, err, lang)
+		return
+	}
+	h.logger.WithError(err).Error("输出IP段失败")
+}
+
+// rangeFilter 从查询参数读取反查条件
+func rangeFilter(c *gin.Context) ipquery.RangeFilter {
+	return ipquery.RangeFilter{
+		Country: c.Query("country"),
+		Region:  c.Query("region"),
+		City:    c.Query("city"),
+		ISP:     c.Query("isp"),
+	}
+}
+
+// statusCode 按错误码返回HTTP状态码，请求错误返回400，其余返回500
+func statusCode(err error) int {
+	switch errors.GetCode(err) {
+	case errors.ErrCodeInvalidIP, errors.ErrCodeInvalidRequest:
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// rangeError 返回反查错误
+func (h *HTTPHandler) rangeError(c *gin.Context, err error, lang i18n.Lang) {
+	h.logger.WithError(err).Error("反查IP段失败")
+	c.JSON(statusCode(err), gin.H{
+		"code":    errors.GetCode(err),
+		"message": errors.GetLocalizedMessage(err, lang),
 	})
 }
 
@@ -103,6 +262,7 @@ func (h *HTTPHandler) GetServiceStatus(c *gin.Context) {
 
 // GetClientIP 获取客户端IP
 func (h *HTTPHandler) GetClientIP(c *gin.Context) {
+	lang := requestLang(c)
 	clientIP := c.ClientIP()
 
 	info, err := h.service.QueryIP(clientIP)
@@ -110,30 +270,172 @@ func (h *HTTPHandler) GetClientIP(c *gin.Context) {
 		h.logger.WithError(err).WithField("ip", clientIP).Error("查询客户端IP失败")
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    errors.GetCode(err),
-			"message": errors.GetMessage(err),
+			"message": errors.GetLocalizedMessage(err, lang),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 0,
-		"data": info,
+		"data": info.Localize(lang),
+	})
+}
+
+// requestLang 根据?lang=参数或Accept-Language头确定响应语言
+func requestLang(c *gin.Context) i18n.Lang {
+	lang := i18n.Resolve(c.Query("lang"), c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", string(lang))
+	return lang
+}
+
+// AdminAuth 校验管理接口令牌(Authorization: Bearer <token>)
+func (h *HTTPHandler) AdminAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if err := h.service.AuthorizeAdmin(token); err != nil {
+			h.logger.WithField("path", c.Request.URL.Path).Warn("管理接口未授权")
+			code := http.StatusUnauthorized
+			if errors.Is(err, errors.ErrCodeForbidden) {
+				code = http.StatusForbidden
+			}
+			c.AbortWithStatusJSON(code, gin.H{
+				"code":    errors.GetCode(err),
+				"message": errors.GetLocalizedMessage(err, requestLang(c)),
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// cacheError 返回缓存管理接口的错误
+func (h *HTTPHandler) cacheError(c *gin.Context, err error) {
+	h.logger.WithError(err).WithField("path", c.Request.URL.Path).Error("缓存管理操作失败")
+	code := http.StatusBadRequest
+	if errors.Is(err, errors.ErrCodeCacheUnavailable) {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{
+		"code":    errors.GetCode(err),
+		"message": errors.GetLocalizedMessage(err, requestLang(c)),
+	})
+}
+
+// GetCacheStats 获取缓存统计及命中率
+func (h *HTTPHandler) GetCacheStats(c *gin.Context) {
+	report, err := h.service.CacheStats()
+	if err != nil {
+		h.cacheError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": report,
+	})
+}
+
+// InspectCache 查看IP的缓存条目及其过期时间
+func (h *HTTPHandler) InspectCache(c *gin.Context) {
+	lang := requestLang(c)
+	ip := strings.TrimSpace(c.Param("ip"))
+
+	entries, err := h.service.InspectCache(ip)
+	if err != nil {
+		h.cacheError(c, err)
+		return
+	}
+	for i := range entries {
+		entries[i].Value = entries[i].Value.Localize(lang)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"ip":      ip,
+			"found":   len(entries) > 0,
+			"entries": entries,
+		},
+	})
+}
+
+// DeleteCacheEntry 删除IP的缓存条目
+func (h *HTTPHandler) DeleteCacheEntry(c *gin.Context) {
+	ip := strings.TrimSpace(c.Param("ip"))
+	if err := h.service.DeleteCacheEntry(ip); err != nil {
+		h.cacheError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{"ip": ip},
+	})
+}
+
+// PurgeCache 删除网段内所有IP的缓存条目
+func (h *HTTPHandler) PurgeCache(c *gin.Context) {
+	prefix := c.Param("addr") + "/" + c.Param("bits")
+	purged, err := h.service.PurgeCache(prefix)
+	if err != nil {
+		h.cacheError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"prefix":  prefix,
+			"deleted": purged,
+		},
+	})
+}
+
+// FlushCache 清空缓存
+func (h *HTTPHandler) FlushCache(c *gin.Context) {
+	if err := h.service.FlushCache(); err != nil {
+		h.cacheError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
 	})
 }
 
 // SetupRoutes 设置路由
 func (h *HTTPHandler) SetupRoutes(router *gin.Engine) {
+	// 每个响应都带上应答所用IP数据库的来源信息
+	router.Use(h.DatabaseHeaders())
+
 	v1 := router.Group("/api/v1")
 	{
 		// IP查询
 		v1.GET("/ip/:ip", h.QueryIP)
 		v1.POST("/ip/batch", h.BatchQueryIP)
 
+		// 网段查询
+		v1.GET("/cidr/:addr/:bits", h.QueryCIDR)
+
+		// IP段反查
+		v1.GET("/ranges", h.FindRanges)
+		v1.GET("/export/:format", h.ExportRanges)
+
 		// 客户端IP查询
 		v1.GET("/ip/client", h.GetClientIP)
 
 		// 服务状态
 		v1.GET("/health", h.HealthCheck)
 		v1.GET("/status", h.GetServiceStatus)
+
+		// 缓存管理，未配置admin.token时不注册
+		if h.service.AdminEnabled() {
+			cache := v1.Group("/cache", h.AdminAuth())
+			cache.GET("/stats", h.GetCacheStats)
+			cache.GET("/ip/:ip", h.InspectCache)
+			cache.DELETE("/ip/:ip", h.DeleteCacheEntry)
+			cache.DELETE("/cidr/:addr/:bits", h.PurgeCache)
+			cache.DELETE("", h.FlushCache)
+		}
 	}
 }
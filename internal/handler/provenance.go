@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ushell/goip/internal/ipquery"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// 标识应答所用IP数据库的响应头，gRPC中以小写形式作为响应头元数据发送
+const (
+	headerDatabaseType      = "X-GoIP-DB-Type"
+	headerDatabaseFile      = "X-GoIP-DB-File"
+	headerDatabaseSHA256    = "X-GoIP-DB-SHA256"
+	headerDatabaseBuildTime = "X-GoIP-DB-Build-Time"
+	headerDatabaseRecords   = "X-GoIP-DB-Records"
+	headerDatabaseLoadedAt  = "X-GoIP-DB-Loaded-At"
+)
+
+// databaseHeaders 根据数据库来源信息生成响应头，多个数据文件的值按文件顺序以逗号分隔
+// 只包含文件名，不暴露服务器上的目录结构，完整路径可通过服务状态接口查看
+func databaseHeaders(info *ipquery.DatabaseInfo) map[string]string {
+	if info == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(info.Files))
+	for _, f := range info.Files {
+		names = append(names, filepath.Base(f.Path))
+	}
+
+	headers := map[string]string{
+		headerDatabaseType:     info.Type,
+		headerDatabaseFile:     strings.Join(names, ","),
+		headerDatabaseSHA256:   strings.Join(info.Checksums(), ","),
+		headerDatabaseRecords:  strconv.Itoa(info.Records()),
+		headerDatabaseLoadedAt: info.LoadedAt.UTC().Format(time.RFC3339),
+	}
+	if buildTime := info.BuildTime(); !buildTime.IsZero() {
+		headers[headerDatabaseBuildTime] = buildTime.UTC().Format(time.RFC3339)
+	}
+	return headers
+}
+
+// databaseMetadata 将数据库来源响应头转换为gRPC元数据
+func databaseMetadata(info *ipquery.DatabaseInfo) metadata.MD {
+	md := metadata.MD{}
+	for key, value := range databaseHeaders(info) {
+		md.Set(key, value)
+	}
+	return md
+}
+
+// DatabaseHeaders 为每个响应添加当前IP数据库的来源信息
+func (h *HTTPHandler) DatabaseHeaders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for key, value := range databaseHeaders(h.service.DatabaseInfo()) {
+			c.Header(key, value)
+		}
+		c.Next()
+	}
+}
+
+// UnaryInterceptor 为每个一元调用的响应头添加当前IP数据库的来源信息
+func (s *GRPCServer) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := grpc.SetHeader(ctx, databaseMetadata(s.service.DatabaseInfo())); err != nil {
+			s.logger.WithError(err).Debug("设置数据库响应头失败")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor 为每个流式调用的响应头添加当前IP数据库的来源信息
+func (s *GRPCServer) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := ss.SetHeader(databaseMetadata(s.service.DatabaseInfo())); err != nil {
+			s.logger.WithError(err).Debug("设置数据库响应头失败")
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,284 @@
+package ipquery
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/oschwald/geoip2-golang"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// ASNRecord 自治系统信息
+type ASNRecord struct {
+	Number       uint32
+	Organization string
+	Prefix       netip.Prefix // 包含查询地址的宣告网段
+}
+
+// ASNDatabase ASN数据库
+type ASNDatabase interface {
+	LookupASN(addr netip.Addr) (*ASNRecord, bool)
+	Close() error
+}
+
+// LoadASNDatabase 按扩展名加载ASN数据库
+// .mmdb为MaxMind GeoLite2-ASN格式，其余按ip2asn TSV格式解析（支持.gz压缩）
+func LoadASNDatabase(path string) (ASNDatabase, error) {
+	if strings.EqualFold(filepath.Ext(path), ".mmdb") {
+		return openMMDBASN(path)
+	}
+	return loadIP2ASN(path)
+}
+
+// mmdbASNDatabase 基于GeoLite2-ASN的ASN数据库
+type mmdbASNDatabase struct {
+	reader *maxminddb.Reader
+}
+
+// openMMDBASN 打开GeoLite2-ASN数据库
+func openMMDBASN(path string) (*mmdbASNDatabase, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open asn database: %w", err)
+	}
+	return &mmdbASNDatabase{reader: reader}, nil
+}
+
+// LookupASN 查询地址所属的自治系统
+func (d *mmdbASNDatabase) LookupASN(addr netip.Addr) (*ASNRecord, bool) {
+	var record geoip2.ASN
+	network, ok, err := d.reader.LookupNetwork(net.IP(addr.Unmap().AsSlice()), &record)
+	if err != nil || !ok || record.AutonomousSystemNumber == 0 {
+		return nil, false
+	}
+
+	result := &ASNRecord{
+		Number:       uint32(record.AutonomousSystemNumber),
+		Organization: record.AutonomousSystemOrganization,
+	}
+	result.Prefix, _ = networkPrefix(network)
+	return result, true
+}
+
+// Close 关闭数据库
+func (d *mmdbASNDatabase) Close() error {
+	return d.reader.Close()
+}
+
+// ip2asnRange ip2asn数据中的一条IP段
+type ip2asnRange struct {
+	start        netip.Addr
+	end          netip.Addr
+	number       uint32
+	organization string
+}
+
+// ip2asnDatabase 基于ip2asn TSV数据（https://iptoasn.com）的ASN数据库
+// 每行为: 起始IP\t结束IP\tAS号\t国家代码\tAS描述，AS号为0表示未宣告
+type ip2asnDatabase struct {
+	ranges []ip2asnRange
+}
+
+// loadIP2ASN 加载ip2asn TSV文件
+func loadIP2ASN(path string) (*ip2asnDatabase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open asn database: %w", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.EqualFold(filepath.Ext(path), ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open asn database: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	// 相同的AS描述只保留一份，降低内存占用
+	organizations := make(map[string]string)
+	ranges := make([]ip2asnRange, 0, 1024)
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "\t", 5)
+		if len(parts) < 3 {
+			return nil, fmt.Errorf("invalid asn range at line %d: %q", lineNo, line)
+		}
+
+		number, err := strconv.ParseUint(parts[2], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid as number at line %d: %w", lineNo, err)
+		}
+		if number == 0 {
+			continue
+		}
+
+		start, err := netip.ParseAddr(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid start ip at line %d: %w", lineNo, err)
+		}
+		end, err := netip.ParseAddr(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid end ip at line %d: %w", lineNo, err)
+		}
+		start, end = start.Unmap(), end.Unmap()
+		if start.BitLen() != end.BitLen() || end.Less(start) {
+			return nil, fmt.Errorf("invalid asn range at line %d: %s-%s", lineNo, start, end)
+		}
+
+		var organization string
+		if len(parts) == 5 {
+			organization = strings.TrimSpace(parts[4])
+			if o, ok := organizations[organization]; ok {
+				organization = o
+			} else {
+				organizations[organization] = organization
+			}
+		}
+
+		ranges = append(ranges, ip2asnRange{
+			start:        start,
+			end:          end,
+			number:       uint32(number),
+			organization: organization,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read asn database: %w", err)
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].start.Less(ranges[j].start)
+	})
+
+	return &ip2asnDatabase{ranges: ranges}, nil
+}
+
+// LookupASN 查询地址所属的自治系统
+// ip2asn将连续的宣告合并为一个IP段，宣告网段取该段的CIDR分解中包含查询地址的一个
+func (d *ip2asnDatabase) LookupASN(addr netip.Addr) (*ASNRecord, bool) {
+	addr = addr.Unmap()
+
+	i := sort.Search(len(d.ranges), func(i int) bool {
+		return addr.Less(d.ranges[i].start)
+	})
+	if i == 0 {
+		return nil, false
+	}
+
+	r := &d.ranges[i-1]
+	if r.end.Less(addr) || r.start.BitLen() != addr.BitLen() {
+		return nil, false
+	}
+
+	record := &ASNRecord{
+		Number:       r.number,
+		Organization: r.organization,
+	}
+	for _, prefix := range RangeToCIDRs(r.start, r.end) {
+		if prefix.Contains(addr) {
+			record.Prefix = prefix
+			break
+		}
+	}
+	return record, true
+}
+
+// Close 关闭数据库
+func (d *ip2asnDatabase) Close() error {
+	return nil
+}
+
+// ASNEnricher 使用本地ASN数据库补充AS号、AS组织和宣告网段
+type ASNEnricher struct {
+	path string
+
+	mu sync.RWMutex
+	db ASNDatabase
+}
+
+// NewASNEnricher 创建ASN增强器
+func NewASNEnricher(path string) (*ASNEnricher, error) {
+	db, err := LoadASNDatabase(path)
+	if err != nil {
+		return nil, err
+	}
+	return &ASNEnricher{path: path, db: db}, nil
+}
+
+// Name 返回增强器名称
+func (e *ASNEnricher) Name() string {
+	return "asn"
+}
+
+// Paths 返回ASN数据库文件
+func (e *ASNEnricher) Paths() []string {
+	return []string{e.path}
+}
+
+// Enrich 补充ASN信息，数据源已提供AS号时保持不变
+func (e *ASNEnricher) Enrich(info *IPInfo) {
+	if info.ASN != 0 {
+		return
+	}
+	addr, err := netip.ParseAddr(strings.TrimSpace(info.IP))
+	if err != nil {
+		return
+	}
+
+	e.mu.RLock()
+	record, ok := e.db.LookupASN(addr)
+	e.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	info.ASN = record.Number
+	info.ASOrganization = record.Organization
+	if record.Prefix.IsValid() {
+		info.ASPrefix = record.Prefix.String()
+	}
+}
+
+// Reload 重新加载ASN数据库
+// 获得写锁时旧数据库上已没有进行中的查询，替换后即可关闭
+func (e *ASNEnricher) Reload() error {
+	db, err := LoadASNDatabase(e.path)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	old := e.db
+	e.db = db
+	e.mu.Unlock()
+
+	return old.Close()
+}
+
+// Close 关闭ASN数据库
+func (e *ASNEnricher) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.Close()
+}
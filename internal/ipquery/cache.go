@@ -1,101 +1,472 @@
 package ipquery
 
 import (
+	"container/list"
+	"fmt"
+	"net/netip"
+	"strings"
 	"sync"
 	"time"
+	"unsafe"
+
+	"github.com/ushell/goip/pkg/logger"
+)
+
+// Cache IP查询结果缓存，实现需要并发安全
+// 缓存后端不可用时Get返回未命中、Set忽略写入，查询总能回退到数据源
+type Cache interface {
+	// Get 获取缓存
+	Get(key string) (*IPInfo, bool)
+	// GetMulti 批量获取缓存，返回命中的键及其结果
+	GetMulti(keys []string) map[string]*IPInfo
+	// Set 设置缓存
+	Set(key string, value *IPInfo)
+	// Inspect 返回键的缓存条目及其过期时间，不影响命中统计和淘汰顺序；多级缓存返回各级中的条目
+	Inspect(key string) []CachedEntry
+	// Delete 删除缓存，缓存后端不可用时返回错误
+	Delete(key string) error
+	// PurgePrefix 删除IP地址属于prefix的所有条目，返回删除的条目数
+	PurgePrefix(prefix netip.Prefix) (int, error)
+	// Clear 清空缓存，缓存后端不可用时返回错误
+	Clear() error
+	// Size 获取缓存条目数
+	Size() int
+	// Stats 获取缓存统计
+	Stats() CacheStats
+	// Close 释放缓存占用的资源
+	Close() error
+}
+
+// CachedEntry 缓存条目的检查结果
+type CachedEntry struct {
+	Key       string     `json:"key"`
+	Cache     string     `json:"cache"` // 条目所在的缓存类型
+	Value     *IPInfo    `json:"value"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // 为空表示永不过期
+}
+
+// cacheKeyInPrefix 判断缓存键（IP地址）是否属于prefix，IPv4映射的IPv6地址按IPv4处理
+func cacheKeyInPrefix(key string, prefix netip.Prefix) bool {
+	addr, err := netip.ParseAddr(strings.TrimSpace(key))
+	if err != nil {
+		return false
+	}
+	return prefix.Contains(addr.Unmap())
+}
+
+// 缓存类型
+const (
+	CacheTypeMemory = "memory"
+	CacheTypeRedis  = "redis"
+	CacheTypeTiered = "tiered" // 本地内存缓存在前、Redis缓存在后的两级缓存
+)
+
+// CacheOptions 创建缓存的选项
+type CacheOptions struct {
+	Type     string        // memory(默认)、redis或tiered
+	TTL      time.Duration // 条目有效期，tiered时为Redis中条目的有效期
+	LocalTTL time.Duration // tiered时本地内存缓存的有效期
+	MaxSize  int
+	MaxBytes int64
+	Policy   CachePolicy
+	Redis    RedisOptions // redis或tiered时使用
+}
+
+// NewCache 根据opts.Type创建缓存
+func NewCache(opts CacheOptions, log *logger.Logger) (Cache, error) {
+	switch opts.Type {
+	case "", CacheTypeMemory:
+		return NewMemoryCache(MemoryCacheOptions{
+			TTL:      opts.TTL,
+			MaxSize:  opts.MaxSize,
+			MaxBytes: opts.MaxBytes,
+			Policy:   opts.Policy,
+		})
+	case CacheTypeRedis:
+		return DialRedisCache(opts.Redis, opts.TTL, log)
+	case CacheTypeTiered:
+		l1, err := NewMemoryCache(MemoryCacheOptions{
+			TTL:      opts.LocalTTL,
+			MaxSize:  opts.MaxSize,
+			MaxBytes: opts.MaxBytes,
+			Policy:   opts.Policy,
+		})
+		if err != nil {
+			return nil, err
+		}
+		l2, err := DialRedisCache(opts.Redis, opts.TTL, log)
+		if err != nil {
+			l1.Close()
+			return nil, err
+		}
+		return NewTieredCache(l1, l2), nil
+	default:
+		return nil, fmt.Errorf("unknown cache type: %s", opts.Type)
+	}
+}
+
+// CachePolicy 缓存淘汰策略
+type CachePolicy string
+
+// 淘汰策略定义
+const (
+	// CachePolicyLRU 淘汰最久未访问的条目
+	CachePolicyLRU CachePolicy = "lru"
+	// CachePolicyLFU 淘汰访问次数最少的条目，次数相同时淘汰最久未访问的条目
+	CachePolicyLFU CachePolicy = "lfu"
+	// CachePolicyTinyLFU 按LRU淘汰，但只有近期访问频率高于被淘汰条目的新条目才会被缓存，
+	// 可避免随机IP扫描把热点条目挤出缓存
+	CachePolicyTinyLFU CachePolicy = "tinylfu"
 )
 
 // CacheItem 缓存项
 type CacheItem struct {
 	Value      *IPInfo
-	Expiration time.Time
+	Expiration time.Time // 零值表示永不过期
+}
+
+// cacheEntry 缓存条目及淘汰策略使用的状态
+type cacheEntry struct {
+	key  string
+	item CacheItem
+	size int64
+
+	// 以下字段由淘汰策略维护
+	elem  *list.Element // LRU链表中的位置
+	index int           // LFU堆中的位置
+	freq  uint64        // LFU访问次数
+	seq   uint64        // LFU最近一次访问的序号
+}
+
+// MemoryCacheOptions 内存缓存配置
+type MemoryCacheOptions struct {
+	TTL      time.Duration // 条目有效期，<=0时永不过期
+	MaxSize  int           // 最大条目数，<=0时不限制
+	MaxBytes int64         // 条目估算占用内存的上限，<=0时不限制
+	Policy   CachePolicy   // 淘汰策略，默认LRU
 }
 
-// MemoryCache 内存缓存
+// CacheStats 缓存统计，不适用于某种缓存类型的字段为零值
+type CacheStats struct {
+	Type        string       `json:"type"`
+	Policy      CachePolicy  `json:"policy,omitempty"`
+	Size        int          `json:"size"`  // Redis需要遍历键空间才能计数，Stats中为0，需要时调用Cache.Size
+	Bytes       int64        `json:"bytes"` // 条目估算占用的内存
+	MaxSize     int          `json:"max_size"`
+	MaxBytes    int64        `json:"max_bytes"`
+	Hits        uint64       `json:"hits"`
+	Misses      uint64       `json:"misses"`
+	Evictions   uint64       `json:"evictions"`       // 因超出容量被淘汰的条目数
+	Expirations uint64       `json:"expirations"`     // 因过期被删除的条目数
+	Rejections  uint64       `json:"rejections"`      // 未被准入或超过内存上限而未缓存的条目数
+	Errors      uint64       `json:"errors"`          // 访问缓存后端失败的次数
+	Tiers       []CacheStats `json:"tiers,omitempty"` // 两级缓存中各级的统计
+}
+
+// MemoryCache 有容量上限的内存缓存
 type MemoryCache struct {
-	items map[string]*CacheItem
-	mu    sync.RWMutex
-	ttl   time.Duration
+	items  map[string]*cacheEntry
+	mu     sync.Mutex
+	opts   MemoryCacheOptions
+	policy evictionPolicy
+	bytes  int64
+	stats  CacheStats
+	stop   chan struct{}
+	once   sync.Once
 }
 
-// NewMemoryCache 创建新的内存缓存
-func NewMemoryCache(ttl time.Duration) *MemoryCache {
+// NewMemoryCache 创建新的内存缓存，TTL大于0时启动后台清理过期条目
+func NewMemoryCache(opts MemoryCacheOptions) (*MemoryCache, error) {
+	if opts.Policy == "" {
+		opts.Policy = CachePolicyLRU
+	}
+	policy, err := newEvictionPolicy(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	cache := &MemoryCache{
-		items: make(map[string]*CacheItem),
-		ttl:   ttl,
+		items:  make(map[string]*cacheEntry),
+		opts:   opts,
+		policy: policy,
+		stop:   make(chan struct{}),
 	}
 
 	// 启动清理goroutine
-	go cache.cleanup()
+	if opts.TTL > 0 {
+		go cache.cleanup()
+	}
 
-	return cache
+	return cache, nil
+}
+
+// newEvictionPolicy 根据配置创建淘汰策略
+func newEvictionPolicy(opts MemoryCacheOptions) (evictionPolicy, error) {
+	switch opts.Policy {
+	case CachePolicyLRU:
+		return newLRUPolicy(), nil
+	case CachePolicyLFU:
+		return newLFUPolicy(), nil
+	case CachePolicyTinyLFU:
+		return newTinyLFUPolicy(opts.MaxSize), nil
+	default:
+		return nil, fmt.Errorf("unknown cache policy: %s", opts.Policy)
+	}
 }
 
 // Get 获取缓存
 func (c *MemoryCache) Get(key string) (*IPInfo, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if admission, ok := c.policy.(admissionPolicy); ok {
+		admission.record(key)
+	}
 
-	item, found := c.items[key]
+	entry, found := c.items[key]
 	if !found {
+		c.stats.Misses++
 		return nil, false
 	}
 
-	if time.Now().After(item.Expiration) {
+	if entry.expired(time.Now()) {
+		c.remove(entry)
+		c.stats.Expirations++
+		c.stats.Misses++
 		return nil, false
 	}
 
-	return item.Value, true
+	c.policy.access(entry)
+	c.stats.Hits++
+	return entry.item.Value, true
 }
 
-// Set 设置缓存
+// GetMulti 批量获取缓存
+func (c *MemoryCache) GetMulti(keys []string) map[string]*IPInfo {
+	results := make(map[string]*IPInfo, len(keys))
+	for _, key := range keys {
+		if value, found := c.Get(key); found {
+			results[key] = value
+		}
+	}
+	return results
+}
+
+// Set 设置缓存，超出容量时按淘汰策略淘汰条目
 func (c *MemoryCache) Set(key string, value *IPInfo) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.items[key] = &CacheItem{
-		Value:      value,
-		Expiration: time.Now().Add(c.ttl),
+	item := CacheItem{Value: value}
+	if c.opts.TTL > 0 {
+		item.Expiration = time.Now().Add(c.opts.TTL)
+	}
+	size := estimateEntrySize(key, value)
+
+	// 更新已缓存的键时不经过准入判断
+	existing, found := c.items[key]
+	if found {
+		c.remove(existing)
 	}
+	if c.opts.MaxBytes > 0 && size > c.opts.MaxBytes {
+		c.stats.Rejections++
+		return
+	}
+
+	if !found && c.full(size) {
+		if admission, ok := c.policy.(admissionPolicy); ok {
+			if victim := c.policy.victim(); victim != nil && !admission.admit(key, victim) {
+				c.stats.Rejections++
+				return
+			}
+		}
+	}
+
+	// 先淘汰再加入新条目，新条目不会成为自己的淘汰对象（如LFU下访问次数最少的新条目）
+	for c.full(size) {
+		victim := c.policy.victim()
+		if victim == nil {
+			break
+		}
+		c.remove(victim)
+		c.stats.Evictions++
+	}
+
+	entry := &cacheEntry{key: key, item: item, size: size}
+	c.items[key] = entry
+	c.bytes += size
+	c.policy.add(entry)
+}
+
+// full 判断加入size字节的新条目是否需要淘汰已有条目
+func (c *MemoryCache) full(size int64) bool {
+	return c.opts.MaxSize > 0 && len(c.items) >= c.opts.MaxSize ||
+		c.opts.MaxBytes > 0 && c.bytes+size > c.opts.MaxBytes
+}
+
+// remove 删除条目，调用方需持有锁
+func (c *MemoryCache) remove(entry *cacheEntry) {
+	delete(c.items, entry.key)
+	c.bytes -= entry.size
+	c.policy.remove(entry)
 }
 
 // Delete 删除缓存
-func (c *MemoryCache) Delete(key string) {
+func (c *MemoryCache) Delete(key string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	delete(c.items, key)
+	if entry, found := c.items[key]; found {
+		c.remove(entry)
+	}
+	return nil
 }
 
-// Clear 清空缓存
-func (c *MemoryCache) Clear() {
+// Inspect 返回未过期的缓存条目
+func (c *MemoryCache) Inspect(key string) []CachedEntry {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.items = make(map[string]*CacheItem)
+	entry, found := c.items[key]
+	if !found || entry.expired(time.Now()) {
+		return nil
+	}
+
+	cached := CachedEntry{
+		Key:   key,
+		Cache: CacheTypeMemory,
+		Value: entry.item.Value,
+	}
+	if !entry.item.Expiration.IsZero() {
+		expiration := entry.item.Expiration
+		cached.ExpiresAt = &expiration
+	}
+	return []CachedEntry{cached}
+}
+
+// PurgePrefix 删除IP地址属于prefix的所有条目
+func (c *MemoryCache) PurgePrefix(prefix netip.Prefix) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	purged := 0
+	for key, entry := range c.items {
+		if cacheKeyInPrefix(key, prefix) {
+			c.remove(entry)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// Clear 清空缓存，统计计数保持不变
+func (c *MemoryCache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[string]*cacheEntry)
+	c.bytes = 0
+	c.policy.reset()
+	return nil
 }
 
 // Size 获取缓存大小
 func (c *MemoryCache) Size() int {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
 	return len(c.items)
 }
 
+// Stats 获取缓存统计
+func (c *MemoryCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Type = CacheTypeMemory
+	stats.Policy = c.opts.Policy
+	stats.Size = len(c.items)
+	stats.Bytes = c.bytes
+	stats.MaxSize = c.opts.MaxSize
+	stats.MaxBytes = c.opts.MaxBytes
+	return stats
+}
+
+// Close 停止后台清理
+func (c *MemoryCache) Close() error {
+	c.once.Do(func() {
+		close(c.stop)
+	})
+	return nil
+}
+
 // cleanup 清理过期缓存
 func (c *MemoryCache) cleanup() {
-	ticker := time.NewTicker(c.ttl / 2)
+	ticker := time.NewTicker(c.opts.TTL / 2)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+		}
+
 		c.mu.Lock()
 		now := time.Now()
-		for key, item := range c.items {
-			if now.After(item.Expiration) {
-				delete(c.items, key)
+		for _, entry := range c.items {
+			if entry.expired(now) {
+				c.remove(entry)
+				c.stats.Expirations++
 			}
 		}
 		c.mu.Unlock()
 	}
 }
+
+// expired 判断条目是否已过期
+func (e *cacheEntry) expired(now time.Time) bool {
+	return !e.item.Expiration.IsZero() && now.After(e.item.Expiration)
+}
+
+// 估算条目大小时使用的固定开销
+const (
+	// mapEntryOverhead map中每个条目的指针、哈希桶等开销
+	mapEntryOverhead = 48
+	// stringHeaderSize 字符串头（指针和长度）的大小
+	stringHeaderSize = int64(unsafe.Sizeof(""))
+)
+
+// estimateEntrySize 估算缓存条目占用的内存，包括键、条目结构以及IPInfo引用的所有字符串
+func estimateEntrySize(key string, info *IPInfo) int64 {
+	size := int64(mapEntryOverhead) + int64(unsafe.Sizeof(cacheEntry{})) + int64(len(key))
+	if info == nil {
+		return size
+	}
+
+	size += int64(unsafe.Sizeof(*info))
+	for _, s := range []string{
+		info.IP, info.Country, info.CountryCode, info.Region, info.City, info.District, info.ISP,
+		info.Timezone, info.PostalCode, info.AdminCode, string(info.AddressType), info.Scope,
+		info.ASOrganization, info.ASPrefix, info.ErrorMessage,
+	} {
+		size += int64(len(s))
+	}
+	for _, tag := range info.Tags {
+		size += stringHeaderSize + int64(len(tag))
+	}
+	if info.Range != nil {
+		size += int64(unsafe.Sizeof(*info.Range)) + int64(len(info.Range.Start)) + int64(len(info.Range.End))
+		for _, cidr := range info.Range.CIDRs {
+			size += stringHeaderSize + int64(len(cidr))
+		}
+	}
+	if info.Threat != nil {
+		size += int64(unsafe.Sizeof(*info.Threat)) + int64(len(info.Threat.UsageType))
+		for _, source := range info.Threat.Sources {
+			size += stringHeaderSize + int64(len(source))
+		}
+	}
+	return size
+}
@@ -0,0 +1,255 @@
+package ipquery
+
+import (
+	"container/heap"
+	"container/list"
+	"hash/maphash"
+)
+
+// evictionPolicy 缓存淘汰策略，所有方法都在缓存持有锁时调用
+type evictionPolicy interface {
+	// add 登记新条目
+	add(e *cacheEntry)
+	// access 记录一次命中
+	access(e *cacheEntry)
+	// remove 移除条目
+	remove(e *cacheEntry)
+	// victim 返回下一个应被淘汰的条目，缓存为空时返回nil
+	victim() *cacheEntry
+	// reset 清空所有条目
+	reset()
+}
+
+// admissionPolicy 在缓存已满时决定新条目能否替换被淘汰条目的准入策略
+type admissionPolicy interface {
+	// record 记录一次对key的访问，无论是否命中
+	record(key string)
+	// admit 判断key是否比victim更值得缓存
+	admit(key string, victim *cacheEntry) bool
+}
+
+// lruPolicy 淘汰最久未访问的条目
+type lruPolicy struct {
+	ll *list.List // 表头为最近访问的条目
+}
+
+func newLRUPolicy() *lruPolicy {
+	return &lruPolicy{ll: list.New()}
+}
+
+func (p *lruPolicy) add(e *cacheEntry) {
+	e.elem = p.ll.PushFront(e)
+}
+
+func (p *lruPolicy) access(e *cacheEntry) {
+	p.ll.MoveToFront(e.elem)
+}
+
+func (p *lruPolicy) remove(e *cacheEntry) {
+	p.ll.Remove(e.elem)
+	e.elem = nil
+}
+
+func (p *lruPolicy) victim() *cacheEntry {
+	if back := p.ll.Back(); back != nil {
+		return back.Value.(*cacheEntry)
+	}
+	return nil
+}
+
+func (p *lruPolicy) reset() {
+	p.ll.Init()
+}
+
+// lfuPolicy 淘汰访问次数最少的条目，次数相同时淘汰最久未访问的条目
+type lfuPolicy struct {
+	h   lfuHeap
+	seq uint64
+}
+
+func newLFUPolicy() *lfuPolicy {
+	return &lfuPolicy{}
+}
+
+func (p *lfuPolicy) add(e *cacheEntry) {
+	p.seq++
+	e.freq, e.seq = 1, p.seq
+	heap.Push(&p.h, e)
+}
+
+func (p *lfuPolicy) access(e *cacheEntry) {
+	p.seq++
+	e.freq++
+	e.seq = p.seq
+	heap.Fix(&p.h, e.index)
+}
+
+func (p *lfuPolicy) remove(e *cacheEntry) {
+	heap.Remove(&p.h, e.index)
+}
+
+func (p *lfuPolicy) victim() *cacheEntry {
+	if len(p.h) == 0 {
+		return nil
+	}
+	return p.h[0]
+}
+
+func (p *lfuPolicy) reset() {
+	p.h = nil
+}
+
+// lfuHeap 按访问次数和最近访问序号排序的最小堆
+type lfuHeap []*cacheEntry
+
+func (h lfuHeap) Len() int { return len(h) }
+
+func (h lfuHeap) Less(i, j int) bool {
+	if h[i].freq != h[j].freq {
+		return h[i].freq < h[j].freq
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h lfuHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *lfuHeap) Push(x interface{}) {
+	e := x.(*cacheEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *lfuHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	e.index = -1
+	return e
+}
+
+// tinyLFUPolicy 以LRU淘汰条目，并用访问频率草图决定新条目的准入
+type tinyLFUPolicy struct {
+	*lruPolicy
+	sketch *countMinSketch
+}
+
+// 频率草图规模
+const (
+	// defaultSketchSamples 未限制条目数时按该条目数设置频率草图
+	defaultSketchSamples = 1 << 16
+	// minSketchWidth 频率草图的最小宽度
+	minSketchWidth = 1024
+)
+
+func newTinyLFUPolicy(maxSize int) *tinyLFUPolicy {
+	if maxSize <= 0 {
+		maxSize = defaultSketchSamples
+	}
+	return &tinyLFUPolicy{
+		lruPolicy: newLRUPolicy(),
+		sketch:    newCountMinSketch(maxSize),
+	}
+}
+
+func (p *tinyLFUPolicy) record(key string) {
+	p.sketch.increment(key)
+}
+
+func (p *tinyLFUPolicy) admit(key string, victim *cacheEntry) bool {
+	return p.sketch.estimate(key) > p.sketch.estimate(victim.key)
+}
+
+// countMinSketch 近似记录键的访问频率，计数器上限为15，
+// 累计记录次数达到缓存容量的10倍时所有计数器减半，使频率反映近期的访问
+type countMinSketch struct {
+	rows      [sketchDepth][]uint8
+	mask      uint64
+	additions int
+	resetAt   int
+	seed      maphash.Seed
+}
+
+// 频率草图参数
+const (
+	sketchDepth      = 4
+	sketchMaxCounter = 15
+)
+
+// newCountMinSketch 按缓存容量创建频率草图，每行宽度为容量的4倍以降低哈希冲突
+func newCountMinSketch(capacity int) *countMinSketch {
+	size := minSketchWidth
+	for size < capacity*4 {
+		size <<= 1
+	}
+
+	s := &countMinSketch{
+		mask:    uint64(size - 1),
+		resetAt: capacity * 10,
+		seed:    maphash.MakeSeed(),
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, size)
+	}
+	return s
+}
+
+// indexes 返回键在各行中的计数器位置
+func (s *countMinSketch) indexes(key string) [sketchDepth]uint64 {
+	h := maphash.String(s.seed, key)
+	h1, h2 := h&0xffffffff, h>>32
+	var idx [sketchDepth]uint64
+	for i := range idx {
+		idx[i] = (h1 + uint64(i)*h2) & s.mask
+	}
+	return idx
+}
+
+// increment 记录一次访问，只增加最小的计数器以减少哈希冲突带来的高估
+func (s *countMinSketch) increment(key string) {
+	idx := s.indexes(key)
+	lowest := s.lowest(idx)
+	if lowest < sketchMaxCounter {
+		for i, j := range idx {
+			if s.rows[i][j] == lowest {
+				s.rows[i][j]++
+			}
+		}
+	}
+
+	s.additions++
+	if s.additions >= s.resetAt {
+		s.halve()
+	}
+}
+
+// estimate 返回键的近似访问频率
+func (s *countMinSketch) estimate(key string) uint8 {
+	return s.lowest(s.indexes(key))
+}
+
+// lowest 返回各行计数器中的最小值
+func (s *countMinSketch) lowest(idx [sketchDepth]uint64) uint8 {
+	lowest := uint8(sketchMaxCounter)
+	for i, j := range idx {
+		if s.rows[i][j] < lowest {
+			lowest = s.rows[i][j]
+		}
+	}
+	return lowest
+}
+
+// halve 所有计数器减半
+func (s *countMinSketch) halve() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.additions /= 2
+}
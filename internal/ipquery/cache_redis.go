@@ -0,0 +1,378 @@
+package ipquery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/ushell/goip/pkg/logger"
+)
+
+// Redis缓存默认值
+const (
+	defaultRedisPrefix        = "goip:"
+	defaultRedisTimeout       = 100 * time.Millisecond
+	defaultRedisRetryInterval = 5 * time.Second
+	// redisScanCount 遍历键时每次SCAN的数量提示
+	redisScanCount = 1000
+)
+
+// RedisCacheOptions Redis缓存配置
+type RedisCacheOptions struct {
+	Prefix        string        // 键前缀，默认goip:，多个服务共用一个Redis时用于区分
+	TTL           time.Duration // 条目有效期，<=0时永不过期
+	Timeout       time.Duration // 单次操作超时，默认100ms
+	RetryInterval time.Duration // Redis出错后暂停访问的时间，默认5s
+	Logger        *logger.Logger
+}
+
+// RedisCache 基于Redis的共享缓存，多个副本可共享已缓存的结果
+// 条目以JSON序列化的IPInfo存储；Redis出错后的RetryInterval内直接返回未命中，
+// 避免每次查询都等待超时，之后自动恢复访问
+type RedisCache struct {
+	client redis.UniversalClient
+	opts   RedisCacheOptions
+
+	downUntil atomic.Int64 // 暂停访问的截止时间(UnixNano)
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	errors    atomic.Uint64
+}
+
+// NewRedisCache 使用已创建的Redis客户端创建缓存
+func NewRedisCache(client redis.UniversalClient, opts RedisCacheOptions) *RedisCache {
+	if opts.Prefix == "" {
+		opts.Prefix = defaultRedisPrefix
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultRedisTimeout
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = defaultRedisRetryInterval
+	}
+	return &RedisCache{
+		client: client,
+		opts:   opts,
+	}
+}
+
+// RedisOptions Redis连接及缓存选项
+type RedisOptions struct {
+	Addr          string
+	Username      string
+	Password      string
+	DB            int
+	PoolSize      int
+	Prefix        string        // 键前缀，默认goip:
+	Timeout       time.Duration // 单次操作超时，默认100ms
+	RetryInterval time.Duration // Redis出错后暂停访问的时间，默认5s
+}
+
+// DialRedisCache 连接Redis并创建缓存，条目有效期为ttl
+// 启动时Redis不可用不会返回错误，缓存会在Redis恢复后自动开始工作
+func DialRedisCache(opts RedisOptions, ttl time.Duration, log *logger.Logger) (*RedisCache, error) {
+	if opts.Addr == "" {
+		return nil, fmt.Errorf("redis address is required")
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     opts.Addr,
+		Username: opts.Username,
+		Password: opts.Password,
+		DB:       opts.DB,
+		PoolSize: opts.PoolSize,
+	})
+
+	cache := NewRedisCache(client, RedisCacheOptions{
+		Prefix:        opts.Prefix,
+		TTL:           ttl,
+		Timeout:       opts.Timeout,
+		RetryInterval: opts.RetryInterval,
+		Logger:        log,
+	})
+
+	ctx, cancel := cache.context()
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		cache.fail(err)
+	}
+	return cache, nil
+}
+
+// context 创建单次操作的超时上下文
+func (c *RedisCache) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.opts.Timeout)
+}
+
+// key 返回带前缀的键
+func (c *RedisCache) key(key string) string {
+	return c.opts.Prefix + key
+}
+
+// available 判断是否可以访问Redis
+func (c *RedisCache) available() bool {
+	return time.Now().UnixNano() >= c.downUntil.Load()
+}
+
+// fail 记录一次Redis错误，并在RetryInterval内暂停访问
+func (c *RedisCache) fail(err error) {
+	c.errors.Add(1)
+	until := time.Now().Add(c.opts.RetryInterval).UnixNano()
+	if prev := c.downUntil.Swap(until); prev < time.Now().UnixNano() && c.opts.Logger != nil {
+		c.opts.Logger.WithError(err).WithField("retry_in", c.opts.RetryInterval.String()).
+			Warn("Redis缓存不可用，暂时跳过缓存")
+	}
+}
+
+// Get 获取缓存，Redis不可用时返回未命中
+func (c *RedisCache) Get(key string) (*IPInfo, bool) {
+	if !c.available() {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	data, err := c.client.Get(ctx, c.key(key)).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			c.fail(err)
+		}
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	info, ok := c.decode(data)
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return info, true
+}
+
+// GetMulti 以流水线批量获取缓存，所有GET在一次往返中完成
+// 使用流水线而非MGET，以便在Redis Cluster中跨槽位批量获取
+func (c *RedisCache) GetMulti(keys []string) map[string]*IPInfo {
+	results := make(map[string]*IPInfo, len(keys))
+	if len(keys) == 0 {
+		return results
+	}
+	if !c.available() {
+		c.misses.Add(uint64(len(keys)))
+		return results
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	cmds := make([]*redis.StringCmd, len(keys))
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, c.key(key))
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		c.fail(err)
+		c.misses.Add(uint64(len(keys)))
+		return results
+	}
+
+	for i, cmd := range cmds {
+		data, err := cmd.Bytes()
+		if err != nil {
+			c.misses.Add(1)
+			continue
+		}
+		info, ok := c.decode(data)
+		if !ok {
+			c.misses.Add(1)
+			continue
+		}
+		results[keys[i]] = info
+		c.hits.Add(1)
+	}
+	return results
+}
+
+// decode 反序列化缓存的IPInfo，数据无法解析时视为未命中
+func (c *RedisCache) decode(data []byte) (*IPInfo, bool) {
+	var info IPInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		c.errors.Add(1)
+		return nil, false
+	}
+	return &info, true
+}
+
+// Set 设置缓存，Redis不可用时忽略
+func (c *RedisCache) Set(key string, value *IPInfo) {
+	if !c.available() {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.errors.Add(1)
+		return
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	ttl := c.opts.TTL
+	if ttl < 0 {
+		ttl = 0
+	}
+	if err := c.client.Set(ctx, c.key(key), data, ttl).Err(); err != nil {
+		c.fail(err)
+	}
+}
+
+// Delete 删除缓存
+func (c *RedisCache) Delete(key string) error {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	if err := c.client.Del(ctx, c.key(key)).Err(); err != nil {
+		c.fail(err)
+		return err
+	}
+	return nil
+}
+
+// Inspect 返回键的缓存条目及其剩余有效期
+func (c *RedisCache) Inspect(key string) []CachedEntry {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	var get *redis.StringCmd
+	var ttl *redis.DurationCmd
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, c.key(key))
+		ttl = pipe.PTTL(ctx, c.key(key))
+		return nil
+	})
+	if err != nil {
+		if err != redis.Nil {
+			c.fail(err)
+		}
+		return nil
+	}
+
+	data, err := get.Bytes()
+	if err != nil {
+		return nil
+	}
+	info, ok := c.decode(data)
+	if !ok {
+		return nil
+	}
+
+	cached := CachedEntry{
+		Key:   key,
+		Cache: CacheTypeRedis,
+		Value: info,
+	}
+	// 没有过期时间的键PTTL返回负值
+	if remaining := ttl.Val(); remaining > 0 {
+		expiration := time.Now().Add(remaining)
+		cached.ExpiresAt = &expiration
+	}
+	return []CachedEntry{cached}
+}
+
+// PurgePrefix 遍历带前缀的键，删除IP地址属于prefix的条目
+// 部分删除后出错时同时返回已删除的条目数和错误
+func (c *RedisCache) PurgePrefix(prefix netip.Prefix) (int, error) {
+	purged := 0
+	err := c.scan(func(ctx context.Context, keys []string) error {
+		matched := make([]string, 0, len(keys))
+		for _, key := range keys {
+			if cacheKeyInPrefix(strings.TrimPrefix(key, c.opts.Prefix), prefix) {
+				matched = append(matched, key)
+			}
+		}
+		if len(matched) == 0 {
+			return nil
+		}
+		n, err := c.client.Unlink(ctx, matched...).Result()
+		purged += int(n)
+		return err
+	})
+	if err != nil {
+		c.fail(err)
+	}
+	return purged, err
+}
+
+// Clear 删除所有带前缀的键，不影响Redis中的其他数据
+func (c *RedisCache) Clear() error {
+	err := c.scan(func(ctx context.Context, keys []string) error {
+		return c.client.Unlink(ctx, keys...).Err()
+	})
+	if err != nil {
+		c.fail(err)
+	}
+	return err
+}
+
+// Size 统计带前缀的键数量，需要遍历整个键空间，仅供管理接口按需调用
+func (c *RedisCache) Size() int {
+	if !c.available() {
+		return 0
+	}
+
+	size := 0
+	err := c.scan(func(ctx context.Context, keys []string) error {
+		size += len(keys)
+		return nil
+	})
+	if err != nil {
+		c.fail(err)
+	}
+	return size
+}
+
+// scan 分批遍历带前缀的键，每批使用独立的超时
+func (c *RedisCache) scan(fn func(ctx context.Context, keys []string) error) error {
+	var cursor uint64
+	for {
+		ctx, cancel := c.context()
+		keys, next, err := c.client.Scan(ctx, cursor, c.opts.Prefix+"*", redisScanCount).Result()
+		if err == nil && len(keys) > 0 {
+			err = fn(ctx, keys)
+		}
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// Stats 获取缓存统计，只读取本地计数器，不访问Redis
+func (c *RedisCache) Stats() CacheStats {
+	return CacheStats{
+		Type:   CacheTypeRedis,
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Errors: c.errors.Load(),
+	}
+}
+
+// Close 关闭Redis连接
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
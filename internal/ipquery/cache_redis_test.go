@@ -0,0 +1,187 @@
+package ipquery
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedisCache 创建连接到进程内miniredis的Redis缓存
+func newTestRedisCache(t *testing.T, opts RedisCacheOptions) (*RedisCache, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{
+		Addr:       mr.Addr(),
+		MaxRetries: -1,
+	})
+	cache := NewRedisCache(client, opts)
+	t.Cleanup(func() { cache.Close() })
+	return cache, mr
+}
+
+func TestRedisCacheKeyPrefix(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{"", "goip:1.2.3.4"},
+		{"test:", "test:1.2.3.4"},
+	}
+	for _, tt := range tests {
+		cache, mr := newTestRedisCache(t, RedisCacheOptions{Prefix: tt.prefix})
+
+		cache.Set("1.2.3.4", &IPInfo{IP: "1.2.3.4", Country: "中国", IsValid: true})
+		if !mr.Exists(tt.want) {
+			t.Fatalf("prefix %q: key %q not found, keys: %v", tt.prefix, tt.want, mr.Keys())
+		}
+
+		info, found := cache.Get("1.2.3.4")
+		if !found {
+			t.Fatalf("prefix %q: Get missed", tt.prefix)
+		}
+		if info.Country != "中国" || !info.IsValid {
+			t.Errorf("prefix %q: Get = %+v", tt.prefix, info)
+		}
+	}
+}
+
+func TestRedisCacheTTL(t *testing.T) {
+	cache, mr := newTestRedisCache(t, RedisCacheOptions{TTL: time.Minute})
+
+	cache.Set("1.2.3.4", &IPInfo{IP: "1.2.3.4", IsValid: true})
+	if ttl := mr.TTL("goip:1.2.3.4"); ttl != time.Minute {
+		t.Fatalf("TTL = %v, want %v", ttl, time.Minute)
+	}
+
+	entries := cache.Inspect("1.2.3.4")
+	if len(entries) != 1 || entries[0].ExpiresAt == nil {
+		t.Fatalf("Inspect = %+v, want one entry with an expiration", entries)
+	}
+	if remaining := time.Until(*entries[0].ExpiresAt); remaining <= 0 || remaining > time.Minute {
+		t.Errorf("Inspect expiration in %v, want within %v", remaining, time.Minute)
+	}
+
+	mr.FastForward(time.Minute + time.Second)
+	if _, found := cache.Get("1.2.3.4"); found {
+		t.Error("Get hit an expired entry")
+	}
+
+	// TTL<=0时永不过期
+	forever, mr := newTestRedisCache(t, RedisCacheOptions{})
+	forever.Set("1.2.3.4", &IPInfo{IP: "1.2.3.4", IsValid: true})
+	if ttl := mr.TTL("goip:1.2.3.4"); ttl != 0 {
+		t.Errorf("TTL without expiry = %v, want none", ttl)
+	}
+}
+
+func TestRedisCacheGetMulti(t *testing.T) {
+	cache, mr := newTestRedisCache(t, RedisCacheOptions{})
+
+	cache.Set("1.1.1.1", &IPInfo{IP: "1.1.1.1", IsValid: true})
+	cache.Set("2.2.2.2", &IPInfo{IP: "2.2.2.2", IsValid: true})
+	// 无法解析的条目视为未命中
+	mr.Set("goip:3.3.3.3", "not json")
+
+	results := cache.GetMulti([]string{"1.1.1.1", "2.2.2.2", "3.3.3.3", "4.4.4.4"})
+	if len(results) != 2 {
+		t.Fatalf("GetMulti returned %d entries, want 2: %v", len(results), results)
+	}
+	for _, key := range []string{"1.1.1.1", "2.2.2.2"} {
+		if info, ok := results[key]; !ok || info.IP != key {
+			t.Errorf("GetMulti[%s] = %+v, %v", key, info, ok)
+		}
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 2 {
+		t.Errorf("hits/misses = %d/%d, want 2/2", stats.Hits, stats.Misses)
+	}
+
+	if results := cache.GetMulti(nil); len(results) != 0 {
+		t.Errorf("GetMulti(nil) = %v", results)
+	}
+}
+
+func TestRedisCacheClearKeepsOtherKeys(t *testing.T) {
+	cache, mr := newTestRedisCache(t, RedisCacheOptions{})
+
+	for _, ip := range []string{"1.1.1.1", "2.2.2.2", "240e::1"} {
+		cache.Set(ip, &IPInfo{IP: ip, IsValid: true})
+	}
+	mr.Set("other:1.1.1.1", "x")
+	mr.Set("session", "y")
+
+	if size := cache.Size(); size != 3 {
+		t.Fatalf("Size = %d, want 3", size)
+	}
+
+	if err := cache.Clear(); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+
+	if size := cache.Size(); size != 0 {
+		t.Errorf("Size after Clear = %d, want 0", size)
+	}
+	for _, key := range []string{"other:1.1.1.1", "session"} {
+		if !mr.Exists(key) {
+			t.Errorf("Clear removed unrelated key %q", key)
+		}
+	}
+}
+
+func TestRedisCacheDegradation(t *testing.T) {
+	const retry = 200 * time.Millisecond
+	cache, mr := newTestRedisCache(t, RedisCacheOptions{
+		Timeout:       50 * time.Millisecond,
+		RetryInterval: retry,
+	})
+	cache.Set("1.1.1.1", &IPInfo{IP: "1.1.1.1", IsValid: true})
+
+	mr.Close()
+	if _, found := cache.Get("1.1.1.1"); found {
+		t.Fatal("Get hit while Redis is stopped")
+	}
+	if errors := cache.Stats().Errors; errors == 0 {
+		t.Error("error counter not incremented")
+	}
+
+	// 管理操作不能静默失败
+	if err := cache.Delete("1.1.1.1"); err == nil {
+		t.Error("Delete returned nil while Redis is stopped")
+	}
+	if _, err := cache.PurgePrefix(netip.MustParsePrefix("1.1.1.0/24")); err == nil {
+		t.Error("PurgePrefix returned nil while Redis is stopped")
+	}
+	if err := cache.Clear(); err == nil {
+		t.Error("Clear returned nil while Redis is stopped")
+	}
+
+	// RetryInterval内不访问Redis：恢复后立即写入被忽略，读取直接未命中
+	if err := mr.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	commands := mr.CommandCount()
+	cache.Set("2.2.2.2", &IPInfo{IP: "2.2.2.2", IsValid: true})
+	if _, found := cache.Get("1.1.1.1"); found {
+		t.Error("Get hit during the retry interval")
+	}
+	if mr.CommandCount() != commands {
+		t.Errorf("%d commands sent during the retry interval", mr.CommandCount()-commands)
+	}
+	if mr.Exists("goip:2.2.2.2") {
+		t.Error("Set wrote to Redis during the retry interval")
+	}
+
+	time.Sleep(retry + 50*time.Millisecond)
+
+	if _, found := cache.Get("1.1.1.1"); !found {
+		t.Error("Get missed after the retry interval")
+	}
+	cache.Set("2.2.2.2", &IPInfo{IP: "2.2.2.2", IsValid: true})
+	if !mr.Exists("goip:2.2.2.2") {
+		t.Error("Set did not resume after the retry interval")
+	}
+}
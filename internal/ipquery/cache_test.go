@@ -0,0 +1,255 @@
+package ipquery
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+var cachePolicies = []CachePolicy{CachePolicyLRU, CachePolicyLFU, CachePolicyTinyLFU}
+
+func newTestMemoryCache(t *testing.T, opts MemoryCacheOptions) *MemoryCache {
+	t.Helper()
+	cache, err := NewMemoryCache(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cache.Close() })
+	return cache
+}
+
+func testIPInfo(key string) *IPInfo {
+	return &IPInfo{IP: key, Country: "中国", CountryCode: "CN", Region: "江苏省", City: "南京市", IsValid: true}
+}
+
+// assertCacheBounds 检查条目数和估算内存没有超过上限，且估算内存与现存条目一致
+func assertCacheBounds(t *testing.T, cache *MemoryCache) {
+	t.Helper()
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if max := cache.opts.MaxSize; max > 0 && len(cache.items) > max {
+		t.Fatalf("%s: %d entries, MaxSize %d", cache.opts.Policy, len(cache.items), max)
+	}
+	if max := cache.opts.MaxBytes; max > 0 && cache.bytes > max {
+		t.Fatalf("%s: %d bytes, MaxBytes %d", cache.opts.Policy, cache.bytes, max)
+	}
+	var bytes int64
+	for _, entry := range cache.items {
+		bytes += entry.size
+	}
+	if bytes != cache.bytes {
+		t.Fatalf("%s: bytes = %d, entries add up to %d", cache.opts.Policy, cache.bytes, bytes)
+	}
+}
+
+func TestMemoryCacheBounds(t *testing.T) {
+	entrySize := estimateEntrySize("10.0.0.100", testIPInfo("10.0.0.100"))
+	limits := []struct {
+		name string
+		opts MemoryCacheOptions
+	}{
+		{"max_size", MemoryCacheOptions{MaxSize: 50}},
+		{"max_bytes", MemoryCacheOptions{MaxBytes: 50 * entrySize}},
+		{"both", MemoryCacheOptions{MaxSize: 40, MaxBytes: 50 * entrySize}},
+	}
+
+	for _, policy := range cachePolicies {
+		for _, limit := range limits {
+			t.Run(string(policy)+"/"+limit.name, func(t *testing.T) {
+				opts := limit.opts
+				opts.Policy = policy
+				cache := newTestMemoryCache(t, opts)
+
+				// 偏斜的访问分布：少数键被频繁访问，使LFU下现存条目的访问次数都大于1
+				rng := rand.New(rand.NewSource(1))
+				for i := 0; i < 20000; i++ {
+					key := fmt.Sprintf("10.0.%d.%d", rng.Intn(4), rng.Intn(rng.Intn(250)+1))
+					if _, found := cache.Get(key); !found {
+						cache.Set(key, testIPInfo(key))
+					}
+					assertCacheBounds(t, cache)
+				}
+				if stats := cache.Stats(); stats.Evictions == 0 && stats.Rejections == 0 {
+					t.Errorf("no evictions or rejections: %+v", stats)
+				}
+			})
+		}
+	}
+}
+
+func TestMemoryCacheBoundsConcurrent(t *testing.T) {
+	for _, policy := range cachePolicies {
+		t.Run(string(policy), func(t *testing.T) {
+			cache := newTestMemoryCache(t, MemoryCacheOptions{MaxSize: 64, MaxBytes: 48 * estimateEntrySize("10.0.0.100", testIPInfo("10.0.0.100")), Policy: policy})
+
+			var wg sync.WaitGroup
+			for g := 0; g < 8; g++ {
+				wg.Add(1)
+				go func(seed int64) {
+					defer wg.Done()
+					rng := rand.New(rand.NewSource(seed))
+					for i := 0; i < 5000; i++ {
+						key := fmt.Sprintf("10.0.%d.%d", rng.Intn(2), rng.Intn(200))
+						if _, found := cache.Get(key); !found {
+							cache.Set(key, testIPInfo(key))
+						}
+						if i%100 == 0 {
+							cache.Delete(key)
+						}
+					}
+				}(int64(g))
+			}
+			wg.Wait()
+			assertCacheBounds(t, cache)
+		})
+	}
+}
+
+func TestMemoryCacheLFUEvictsWhenAllEntriesAreHot(t *testing.T) {
+	cache := newTestMemoryCache(t, MemoryCacheOptions{MaxSize: 3, Policy: CachePolicyLFU})
+
+	for _, key := range []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"} {
+		cache.Set(key, testIPInfo(key))
+		for i := 0; i < 3; i++ {
+			cache.Get(key)
+		}
+	}
+	cache.Get("1.1.1.1")
+
+	// 新条目的访问次数为1，少于所有现存条目，但仍应替换访问次数最少的现存条目
+	cache.Set("4.4.4.4", testIPInfo("4.4.4.4"))
+	assertCacheBounds(t, cache)
+	if size := cache.Size(); size != 3 {
+		t.Fatalf("Size = %d, want 3", size)
+	}
+	if _, found := cache.Get("4.4.4.4"); !found {
+		t.Error("newly set entry was evicted immediately")
+	}
+	if _, found := cache.Get("2.2.2.2"); found {
+		t.Error("2.2.2.2 should have been evicted: fewest hits and least recently used")
+	}
+	if _, found := cache.Get("1.1.1.1"); !found {
+		t.Error("most frequently used entry was evicted")
+	}
+	if stats := cache.Stats(); stats.Evictions != 1 {
+		t.Errorf("Evictions = %d, want 1", stats.Evictions)
+	}
+}
+
+func TestMemoryCacheLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newTestMemoryCache(t, MemoryCacheOptions{MaxSize: 2, Policy: CachePolicyLRU})
+
+	cache.Set("1.1.1.1", testIPInfo("1.1.1.1"))
+	cache.Set("2.2.2.2", testIPInfo("2.2.2.2"))
+	cache.Get("1.1.1.1")
+	cache.Set("3.3.3.3", testIPInfo("3.3.3.3"))
+
+	if _, found := cache.Get("2.2.2.2"); found {
+		t.Error("least recently used entry was kept")
+	}
+	for _, key := range []string{"1.1.1.1", "3.3.3.3"} {
+		if _, found := cache.Get(key); !found {
+			t.Errorf("%s was evicted", key)
+		}
+	}
+	if stats := cache.Stats(); stats.Evictions != 1 || stats.Size != 2 {
+		t.Errorf("Evictions = %d, Size = %d; want 1, 2", stats.Evictions, stats.Size)
+	}
+}
+
+func TestMemoryCacheTinyLFUAdmission(t *testing.T) {
+	cache := newTestMemoryCache(t, MemoryCacheOptions{MaxSize: 2, Policy: CachePolicyTinyLFU})
+
+	for _, key := range []string{"1.1.1.1", "2.2.2.2"} {
+		cache.Set(key, testIPInfo(key))
+		for i := 0; i < 5; i++ {
+			cache.Get(key)
+		}
+	}
+
+	// 只访问过一次的键不能替换热点条目
+	if _, found := cache.Get("9.9.9.9"); found {
+		t.Fatal("unexpected hit")
+	}
+	cache.Set("9.9.9.9", testIPInfo("9.9.9.9"))
+	if _, found := cache.Get("9.9.9.9"); found {
+		t.Error("one-hit key was admitted over a hot victim")
+	}
+	for _, key := range []string{"1.1.1.1", "2.2.2.2"} {
+		if _, found := cache.Get(key); !found {
+			t.Errorf("hot entry %s was evicted", key)
+		}
+	}
+	stats := cache.Stats()
+	if stats.Rejections != 1 || stats.Evictions != 0 {
+		t.Errorf("Rejections = %d, Evictions = %d; want 1, 0", stats.Rejections, stats.Evictions)
+	}
+
+	// 访问频率超过被淘汰条目后准入
+	for i := 0; i < 10; i++ {
+		cache.Get("9.9.9.9")
+	}
+	cache.Set("9.9.9.9", testIPInfo("9.9.9.9"))
+	if _, found := cache.Get("9.9.9.9"); !found {
+		t.Error("frequently requested key was not admitted")
+	}
+	if stats := cache.Stats(); stats.Evictions != 1 {
+		t.Errorf("Evictions = %d, want 1", stats.Evictions)
+	}
+}
+
+func TestMemoryCacheRejectsOversizedEntry(t *testing.T) {
+	info := testIPInfo("1.1.1.1")
+	cache := newTestMemoryCache(t, MemoryCacheOptions{MaxBytes: estimateEntrySize("1.1.1.1", info) - 1})
+
+	cache.Set("1.1.1.1", info)
+	if _, found := cache.Get("1.1.1.1"); found {
+		t.Error("entry larger than MaxBytes was cached")
+	}
+	if stats := cache.Stats(); stats.Rejections != 1 || stats.Bytes != 0 {
+		t.Errorf("Rejections = %d, Bytes = %d; want 1, 0", stats.Rejections, stats.Bytes)
+	}
+}
+
+func TestMemoryCacheTTL(t *testing.T) {
+	const ttl = 100 * time.Millisecond
+	for _, policy := range cachePolicies {
+		t.Run(string(policy), func(t *testing.T) {
+			cache := newTestMemoryCache(t, MemoryCacheOptions{TTL: ttl, Policy: policy})
+
+			cache.Set("1.1.1.1", testIPInfo("1.1.1.1"))
+			cache.Set("2.2.2.2", testIPInfo("2.2.2.2"))
+			entries := cache.Inspect("1.1.1.1")
+			if len(entries) != 1 || entries[0].ExpiresAt == nil || time.Until(*entries[0].ExpiresAt) > ttl {
+				t.Fatalf("Inspect = %+v, want one entry expiring within %v", entries, ttl)
+			}
+			if _, found := cache.Get("1.1.1.1"); !found {
+				t.Fatal("Get missed before expiry")
+			}
+
+			// 过期条目由Get或后台清理删除，两种情况都计入Expirations
+			time.Sleep(ttl + 20*time.Millisecond)
+			if _, found := cache.Get("1.1.1.1"); found {
+				t.Error("Get hit an expired entry")
+			}
+			deadline := time.Now().Add(time.Second)
+			for cache.Size() > 0 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			stats := cache.Stats()
+			if stats.Size != 0 || stats.Bytes != 0 {
+				t.Errorf("Size = %d, Bytes = %d after expiry; want 0, 0", stats.Size, stats.Bytes)
+			}
+			if stats.Expirations != 2 || stats.Evictions != 0 {
+				t.Errorf("Expirations = %d, Evictions = %d; want 2, 0", stats.Expirations, stats.Evictions)
+			}
+			if stats.Hits != 1 || stats.Misses != 1 {
+				t.Errorf("Hits = %d, Misses = %d; want 1, 1", stats.Hits, stats.Misses)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package ipquery
+
+import (
+	"errors"
+	"net/netip"
+	"sync/atomic"
+)
+
+// TieredCache 两级缓存：L1为进程内缓存，L2为多个副本共享的缓存
+// 读取时先查L1，未命中再查L2，L2命中的结果提升到L1；写入时同时写入两级。
+// 两级各自使用独立的有效期，L1通常设置较短的有效期，使各副本较快看到L2中的变化
+type TieredCache struct {
+	l1, l2 Cache
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// NewTieredCache 创建两级缓存
+func NewTieredCache(l1, l2 Cache) *TieredCache {
+	return &TieredCache{l1: l1, l2: l2}
+}
+
+// Get 获取缓存，L2命中时提升到L1
+func (c *TieredCache) Get(key string) (*IPInfo, bool) {
+	if value, found := c.l1.Get(key); found {
+		c.hits.Add(1)
+		return value, true
+	}
+
+	value, found := c.l2.Get(key)
+	if !found {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.l1.Set(key, value)
+	c.hits.Add(1)
+	return value, true
+}
+
+// GetMulti 批量获取缓存，L1未命中的键一次性从L2读取，L2命中的结果提升到L1
+func (c *TieredCache) GetMulti(keys []string) map[string]*IPInfo {
+	results := c.l1.GetMulti(keys)
+
+	missing := make([]string, 0, len(keys)-len(results))
+	for _, key := range keys {
+		if _, found := results[key]; !found {
+			missing = append(missing, key)
+		}
+	}
+
+	if len(missing) > 0 {
+		for key, value := range c.l2.GetMulti(missing) {
+			c.l1.Set(key, value)
+			results[key] = value
+		}
+	}
+
+	c.hits.Add(uint64(len(results)))
+	c.misses.Add(uint64(len(keys) - len(results)))
+	return results
+}
+
+// Set 同时写入两级缓存
+func (c *TieredCache) Set(key string, value *IPInfo) {
+	c.l1.Set(key, value)
+	c.l2.Set(key, value)
+}
+
+// Delete 从两级缓存中删除，L2删除失败时仍删除L1中的条目
+func (c *TieredCache) Delete(key string) error {
+	return errors.Join(c.l2.Delete(key), c.l1.Delete(key))
+}
+
+// Inspect 依次返回L1和L2中的条目
+func (c *TieredCache) Inspect(key string) []CachedEntry {
+	return append(c.l1.Inspect(key), c.l2.Inspect(key)...)
+}
+
+// PurgePrefix 从两级缓存中删除IP地址属于prefix的条目
+// L1中的条目是L2条目的副本，返回两级中删除条目较多的一级的数量
+func (c *TieredCache) PurgePrefix(prefix netip.Prefix) (int, error) {
+	l2, err2 := c.l2.PurgePrefix(prefix)
+	l1, err1 := c.l1.PurgePrefix(prefix)
+	return max(l2, l1), errors.Join(err2, err1)
+}
+
+// Clear 清空两级缓存
+// 先清空L2，避免清空L1后立即从L2提升旧条目；其他副本的L1在有效期后过期
+func (c *TieredCache) Clear() error {
+	return errors.Join(c.l2.Clear(), c.l1.Clear())
+}
+
+// Size 返回L2中的条目数，L1中的条目总是L2条目的子集
+func (c *TieredCache) Size() int {
+	return c.l2.Size()
+}
+
+// Stats 返回整体命中统计以及各级缓存的统计
+func (c *TieredCache) Stats() CacheStats {
+	l1, l2 := c.l1.Stats(), c.l2.Stats()
+	return CacheStats{
+		Type:   CacheTypeTiered,
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Errors: l1.Errors + l2.Errors,
+		Tiers:  []CacheStats{l1, l2},
+	}
+}
+
+// Close 关闭两级缓存
+func (c *TieredCache) Close() error {
+	return errors.Join(c.l1.Close(), c.l2.Close())
+}
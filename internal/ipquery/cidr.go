@@ -0,0 +1,124 @@
+package ipquery
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// IPRange IP地址范围
+type IPRange struct {
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+	CIDRs []string `json:"cidrs"` // 恰好覆盖该范围的最少CIDR列表
+}
+
+// NewIPRange 根据起止地址创建IP范围
+func NewIPRange(start, end netip.Addr) *IPRange {
+	prefixes := RangeToCIDRs(start, end)
+	cidrs := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		cidrs = append(cidrs, prefix.String())
+	}
+
+	return &IPRange{
+		Start: start.String(),
+		End:   end.String(),
+		CIDRs: cidrs,
+	}
+}
+
+// RangeToCIDRs 将起止地址转换为恰好覆盖该范围的最少CIDR列表
+// 起止地址必须属于同一地址族且start<=end，否则返回nil
+func RangeToCIDRs(start, end netip.Addr) []netip.Prefix {
+	start, end = start.Unmap(), end.Unmap()
+	if !start.IsValid() || !end.IsValid() || start.BitLen() != end.BitLen() || end.Less(start) {
+		return nil
+	}
+
+	var prefixes []netip.Prefix
+	for {
+		// 选择以start为起点、不超过end的最大网段
+		var prefix netip.Prefix
+		for bits := 0; bits <= start.BitLen(); bits++ {
+			candidate := netip.PrefixFrom(start, bits)
+			if candidate.Masked().Addr() != start {
+				continue
+			}
+			if last := LastAddr(candidate); !end.Less(last) {
+				prefix = candidate
+				break
+			}
+		}
+
+		prefixes = append(prefixes, prefix)
+
+		last := LastAddr(prefix)
+		if last == end {
+			return prefixes
+		}
+		start = last.Next()
+	}
+}
+
+// parseCIDROrAddr 解析CIDR，单个IP地址视为/32或/128，IPv4映射的网段转换为IPv4网段
+func parseCIDROrAddr(cidr string) (netip.Prefix, error) {
+	cidr = strings.TrimSpace(cidr)
+	if !strings.Contains(cidr, "/") {
+		addr, err := netip.ParseAddr(cidr)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+	return prefix.Masked(), nil
+}
+
+// LastAddr 返回网段中的最后一个地址
+func LastAddr(prefix netip.Prefix) netip.Addr {
+	addr := prefix.Masked().Addr()
+	bits := prefix.Bits()
+
+	if addr.Is4() {
+		b := addr.As4()
+		setHostBits(b[:], bits)
+		return netip.AddrFrom4(b)
+	}
+
+	b := addr.As16()
+	setHostBits(b[:], bits)
+	return netip.AddrFrom16(b)
+}
+
+// setHostBits 将前缀长度之后的所有位置为1
+func setHostBits(b []byte, bits int) {
+	for i := range b {
+		switch {
+		case bits >= (i+1)*8:
+			// 网络位，保持不变
+		case bits <= i*8:
+			b[i] = 0xFF
+		default:
+			b[i] |= 0xFF >> (bits - i*8)
+		}
+	}
+}
+
+// uint32ToAddr 将整数形式的IPv4地址转换为netip.Addr
+func uint32ToAddr(ip uint32) netip.Addr {
+	return netip.AddrFrom4([4]byte{byte(ip >> 24), byte(ip >> 16), byte(ip >> 8), byte(ip)})
+}
+
+// addrToUint32 将IPv4地址转换为整数形式
+func addrToUint32(addr netip.Addr) uint32 {
+	b := addr.As4()
+	return uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+}
@@ -0,0 +1,132 @@
+package ipquery
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// AddressType 地址类型
+type AddressType string
+
+// 地址类型定义
+const (
+	AddressTypePrivate       AddressType = "private"       // 私有地址（RFC 1918、唯一本地地址）
+	AddressTypeLoopback      AddressType = "loopback"      // 环回地址
+	AddressTypeLinkLocal     AddressType = "link_local"    // 链路本地地址
+	AddressTypeCGNAT         AddressType = "cgnat"         // 运营商级NAT共享地址（RFC 6598）
+	AddressTypeMulticast     AddressType = "multicast"     // 组播地址
+	AddressTypeReserved      AddressType = "reserved"      // 其他特殊用途或保留地址
+	AddressTypeDocumentation AddressType = "documentation" // 文档示例地址
+	AddressTypeGlobal        AddressType = "global"        // 全局单播地址
+)
+
+// AddressClass 地址分类结果
+type AddressClass struct {
+	Type   AddressType
+	Scope  string       // 命中的特殊用途地址块名称，全局单播地址为空
+	Prefix netip.Prefix // 命中的特殊用途地址块，全局单播地址为零值
+}
+
+// specialBlock 特殊用途地址块
+type specialBlock struct {
+	prefix netip.Prefix
+	class  AddressType
+	scope  string
+}
+
+// specialBlocks IANA特殊用途地址注册表（RFC 6890及后续更新）
+// 分类时按最长前缀匹配，因此更具体的地址块可以覆盖其所在的大块
+var specialBlocks = []specialBlock{
+	// IPv4
+	{netip.MustParsePrefix("0.0.0.0/8"), AddressTypeReserved, "this_network"},
+	{netip.MustParsePrefix("10.0.0.0/8"), AddressTypePrivate, "private_use"},
+	{netip.MustParsePrefix("100.64.0.0/10"), AddressTypeCGNAT, "shared_address_space"},
+	{netip.MustParsePrefix("127.0.0.0/8"), AddressTypeLoopback, "loopback"},
+	{netip.MustParsePrefix("169.254.0.0/16"), AddressTypeLinkLocal, "link_local"},
+	{netip.MustParsePrefix("172.16.0.0/12"), AddressTypePrivate, "private_use"},
+	{netip.MustParsePrefix("192.0.0.0/24"), AddressTypeReserved, "ietf_protocol_assignments"},
+	{netip.MustParsePrefix("192.0.0.9/32"), AddressTypeGlobal, "port_control_protocol_anycast"},
+	{netip.MustParsePrefix("192.0.0.10/32"), AddressTypeGlobal, "turn_anycast"},
+	{netip.MustParsePrefix("192.0.2.0/24"), AddressTypeDocumentation, "test_net_1"},
+	{netip.MustParsePrefix("192.31.196.0/24"), AddressTypeGlobal, "as112"},
+	{netip.MustParsePrefix("192.52.193.0/24"), AddressTypeGlobal, "amt"},
+	{netip.MustParsePrefix("192.88.99.0/24"), AddressTypeReserved, "6to4_relay_anycast"},
+	{netip.MustParsePrefix("192.168.0.0/16"), AddressTypePrivate, "private_use"},
+	{netip.MustParsePrefix("192.175.48.0/24"), AddressTypeGlobal, "direct_delegation_as112"},
+	{netip.MustParsePrefix("198.18.0.0/15"), AddressTypeReserved, "benchmarking"},
+	{netip.MustParsePrefix("198.51.100.0/24"), AddressTypeDocumentation, "test_net_2"},
+	{netip.MustParsePrefix("203.0.113.0/24"), AddressTypeDocumentation, "test_net_3"},
+	{netip.MustParsePrefix("224.0.0.0/4"), AddressTypeMulticast, "multicast"},
+	{netip.MustParsePrefix("240.0.0.0/4"), AddressTypeReserved, "future_use"},
+	{netip.MustParsePrefix("255.255.255.255/32"), AddressTypeReserved, "limited_broadcast"},
+
+	// IPv6，IPv4映射地址（::ffff:0:0/96）按内嵌的IPv4地址分类
+	{netip.MustParsePrefix("::/128"), AddressTypeReserved, "unspecified"},
+	{netip.MustParsePrefix("::1/128"), AddressTypeLoopback, "loopback"},
+	{netip.MustParsePrefix("64:ff9b::/96"), AddressTypeGlobal, "nat64"},
+	{netip.MustParsePrefix("64:ff9b:1::/48"), AddressTypeReserved, "nat64_local_use"},
+	{netip.MustParsePrefix("100::/64"), AddressTypeReserved, "discard_only"},
+	{netip.MustParsePrefix("2001::/23"), AddressTypeReserved, "ietf_protocol_assignments"},
+	{netip.MustParsePrefix("2001::/32"), AddressTypeReserved, "teredo"},
+	{netip.MustParsePrefix("2001:1::1/128"), AddressTypeGlobal, "port_control_protocol_anycast"},
+	{netip.MustParsePrefix("2001:1::2/128"), AddressTypeGlobal, "turn_anycast"},
+	{netip.MustParsePrefix("2001:2::/48"), AddressTypeReserved, "benchmarking"},
+	{netip.MustParsePrefix("2001:3::/32"), AddressTypeGlobal, "amt"},
+	{netip.MustParsePrefix("2001:4:112::/48"), AddressTypeGlobal, "as112"},
+	{netip.MustParsePrefix("2001:10::/28"), AddressTypeReserved, "orchid"},
+	{netip.MustParsePrefix("2001:20::/28"), AddressTypeGlobal, "orchid_v2"},
+	{netip.MustParsePrefix("2001:db8::/32"), AddressTypeDocumentation, "documentation"},
+	{netip.MustParsePrefix("2002::/16"), AddressTypeReserved, "6to4"},
+	{netip.MustParsePrefix("2620:4f:8000::/48"), AddressTypeGlobal, "direct_delegation_as112"},
+	{netip.MustParsePrefix("3fff::/20"), AddressTypeDocumentation, "documentation"},
+	{netip.MustParsePrefix("5f00::/16"), AddressTypeReserved, "srv6_sids"},
+	{netip.MustParsePrefix("fc00::/7"), AddressTypePrivate, "unique_local"},
+	{netip.MustParsePrefix("fe80::/10"), AddressTypeLinkLocal, "link_local"},
+	{netip.MustParsePrefix("ff00::/8"), AddressTypeMulticast, "multicast"},
+}
+
+// Classify 按IANA特殊用途地址注册表对地址分类，未命中任何地址块的地址为全局单播地址
+func Classify(addr netip.Addr) AddressClass {
+	addr = addr.Unmap()
+
+	var matched *specialBlock
+	for i := range specialBlocks {
+		block := &specialBlocks[i]
+		if block.prefix.Contains(addr) && (matched == nil || block.prefix.Bits() > matched.prefix.Bits()) {
+			matched = block
+		}
+	}
+
+	if matched == nil {
+		return AddressClass{Type: AddressTypeGlobal}
+	}
+	return AddressClass{
+		Type:   matched.class,
+		Scope:  matched.scope,
+		Prefix: matched.prefix,
+	}
+}
+
+// ClassifyIP 对字符串形式的IP地址分类，无效地址返回false
+func ClassifyIP(ip string) (AddressClass, bool) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return AddressClass{}, false
+	}
+	return Classify(addr), true
+}
+
+// Classify 设置IP信息的地址类型和范围
+// 特殊用途地址在数据源未提供范围时使用所属地址块作为范围
+func (info *IPInfo) Classify() {
+	class, ok := ClassifyIP(info.IP)
+	if !ok {
+		return
+	}
+
+	info.AddressType = class.Type
+	info.Scope = class.Scope
+	if info.Range == nil && class.Prefix.IsValid() {
+		info.Range = NewIPRange(class.Prefix.Addr(), LastAddr(class.Prefix))
+	}
+}
@@ -0,0 +1,416 @@
+package ipquery
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+func init() {
+	RegisterProvider("composite", ProviderRegistration{
+		Factory: newCompositeProviderFromOptions,
+		Paths: func(opts DatabaseOptions) []string {
+			var paths []string
+			for _, member := range opts.Composite.Members {
+				memberOpts := opts
+				memberOpts.Type = member.Type
+				memberPaths, err := DatabasePaths(memberOpts)
+				if err != nil {
+					continue
+				}
+				paths = append(paths, memberPaths...)
+			}
+			return paths
+		},
+	})
+}
+
+// mergeField 可合并的IPInfo字段
+type mergeField struct {
+	isEmpty func(info *IPInfo) bool
+	copy    func(dst, src *IPInfo)
+}
+
+// mergeFields 按配置名称索引的可合并字段
+// location同时包含纬度和经度，asn同时包含AS号、AS组织和宣告网段，各自总是来自同一个数据源
+var mergeFields = map[string]mergeField{
+	"country": {
+		isEmpty: func(info *IPInfo) bool { return info.Country == "" },
+		copy:    func(dst, src *IPInfo) { dst.Country = src.Country },
+	},
+	"country_code": {
+		isEmpty: func(info *IPInfo) bool { return info.CountryCode == "" },
+		copy:    func(dst, src *IPInfo) { dst.CountryCode = src.CountryCode },
+	},
+	"region": {
+		isEmpty: func(info *IPInfo) bool { return info.Region == "" },
+		copy:    func(dst, src *IPInfo) { dst.Region = src.Region },
+	},
+	"city": {
+		isEmpty: func(info *IPInfo) bool { return info.City == "" },
+		copy:    func(dst, src *IPInfo) { dst.City = src.City },
+	},
+	"district": {
+		isEmpty: func(info *IPInfo) bool { return info.District == "" },
+		copy:    func(dst, src *IPInfo) { dst.District = src.District },
+	},
+	"isp": {
+		isEmpty: func(info *IPInfo) bool { return info.ISP == "" },
+		copy:    func(dst, src *IPInfo) { dst.ISP = src.ISP },
+	},
+	"location": {
+		isEmpty: func(info *IPInfo) bool { return info.Latitude == 0 && info.Longitude == 0 },
+		copy: func(dst, src *IPInfo) {
+			dst.Latitude = src.Latitude
+			dst.Longitude = src.Longitude
+		},
+	},
+	"timezone": {
+		isEmpty: func(info *IPInfo) bool { return info.Timezone == "" },
+		copy:    func(dst, src *IPInfo) { dst.Timezone = src.Timezone },
+	},
+	"postal_code": {
+		isEmpty: func(info *IPInfo) bool { return info.PostalCode == "" },
+		copy:    func(dst, src *IPInfo) { dst.PostalCode = src.PostalCode },
+	},
+	"asn": {
+		isEmpty: func(info *IPInfo) bool { return info.ASN == 0 },
+		copy: func(dst, src *IPInfo) {
+			dst.ASN = src.ASN
+			dst.ASOrganization = src.ASOrganization
+			dst.ASPrefix = src.ASPrefix
+		},
+	},
+	"range": {
+		isEmpty: func(info *IPInfo) bool { return info.Range == nil },
+		copy:    func(dst, src *IPInfo) { dst.Range = src.Range },
+	},
+}
+
+// CompositeMember 组合提供者成员
+type CompositeMember struct {
+	Name     string
+	Provider QueryProvider
+	Timeout  time.Duration // 单次查询超时，<=0表示不限制
+	Fields   []string      // 该成员可提供的字段，为空表示全部字段
+}
+
+// CompositeProvider 组合多个提供者的查询提供者
+// 所有成员并发查询，结果按字段合并：每个字段取优先级最高且非空的成员结果
+type CompositeProvider struct {
+	members     []CompositeMember
+	precedence  map[string][]int // 字段 -> 成员下标的优先顺序
+	inflight    sync.WaitGroup   // 包括已超时但尚未返回的成员查询
+	initialized bool
+}
+
+// NewCompositeProvider 创建新的组合提供者
+// members的顺序即默认优先级，precedence可按字段覆盖优先级（值为成员名称列表）
+func NewCompositeProvider(members []CompositeMember, precedence map[string][]string) (*CompositeProvider, error) {
+	if len(members) == 0 {
+		return nil, fmt.Errorf("composite provider requires at least one member")
+	}
+
+	index := make(map[string]int, len(members))
+	for i, member := range members {
+		if _, exists := index[member.Name]; exists {
+			return nil, fmt.Errorf("duplicate composite member %q", member.Name)
+		}
+		for _, field := range member.Fields {
+			if _, ok := mergeFields[field]; !ok {
+				return nil, fmt.Errorf("unknown field %q for composite member %q", field, member.Name)
+			}
+		}
+		index[member.Name] = i
+	}
+
+	order := make(map[string][]int, len(mergeFields))
+	for field := range mergeFields {
+		names, ok := precedence[field]
+		if !ok {
+			// 默认按成员顺序
+			for i := range members {
+				order[field] = append(order[field], i)
+			}
+			continue
+		}
+
+		for _, name := range names {
+			i, exists := index[name]
+			if !exists {
+				return nil, fmt.Errorf("unknown composite member %q in precedence of %q", name, field)
+			}
+			order[field] = append(order[field], i)
+		}
+	}
+	for field := range precedence {
+		if _, ok := mergeFields[field]; !ok {
+			return nil, fmt.Errorf("unknown field %q in composite precedence", field)
+		}
+	}
+
+	return &CompositeProvider{
+		members:     members,
+		precedence:  order,
+		initialized: true,
+	}, nil
+}
+
+// newCompositeProviderFromOptions 根据选项创建组合提供者，成员通过注册表创建
+func newCompositeProviderFromOptions(opts DatabaseOptions) (QueryProvider, error) {
+	members := make([]CompositeMember, 0, len(opts.Composite.Members))
+	closeAll := func() {
+		for _, member := range members {
+			member.Provider.Close()
+		}
+	}
+
+	for _, member := range opts.Composite.Members {
+		if member.Type == "composite" {
+			closeAll()
+			return nil, fmt.Errorf("composite provider cannot be nested")
+		}
+
+		memberOpts := opts
+		memberOpts.Type = member.Type
+		provider, err := NewProvider(memberOpts)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("failed to create composite member %q: %w", member.Type, err)
+		}
+
+		members = append(members, CompositeMember{
+			Name:     member.Type,
+			Provider: provider,
+			Timeout:  member.Timeout,
+			Fields:   member.Fields,
+		})
+	}
+
+	provider, err := NewCompositeProvider(members, opts.Composite.Precedence)
+	if err != nil {
+		closeAll()
+		return nil, err
+	}
+	return provider, nil
+}
+
+// memberResult 成员查询结果
+type memberResult struct {
+	index int
+	info  *IPInfo
+	err   error
+}
+
+// Query 查询单个IP地址信息
+func (p *CompositeProvider) Query(ip string) (*IPInfo, error) {
+	if !p.initialized {
+		return nil, fmt.Errorf("provider not initialized")
+	}
+
+	if !ValidateIP(ip) {
+		return &IPInfo{
+			IP:           ip,
+			IsValid:      false,
+			ErrorMessage: "无效的IP地址格式",
+		}, nil
+	}
+
+	results := p.queryMembers(ip)
+
+	var firstInvalid *IPInfo
+	var firstErr error
+	valid := make([]*IPInfo, len(p.members))
+	found := false
+	for i, result := range results {
+		switch {
+		case result == nil:
+			// 超时
+		case result.err != nil:
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", p.members[i].Name, result.err)
+			}
+		case !result.info.IsValid:
+			if firstInvalid == nil {
+				firstInvalid = result.info
+			}
+		default:
+			valid[i] = result.info
+			found = true
+		}
+	}
+
+	if !found {
+		if firstInvalid != nil {
+			return firstInvalid, nil
+		}
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		return &IPInfo{
+			IP:           ip,
+			IsValid:      false,
+			ErrorMessage: "IP查询失败: 所有数据源均超时",
+		}, nil
+	}
+
+	return p.merge(ip, valid), nil
+}
+
+// queryMembers 并发查询所有成员，超时成员的结果为nil
+func (p *CompositeProvider) queryMembers(ip string) []*memberResult {
+	start := time.Now()
+	ch := make(chan memberResult, len(p.members))
+	for i, member := range p.members {
+		p.inflight.Add(1)
+		go func(i int, provider QueryProvider) {
+			defer p.inflight.Done()
+			info, err := provider.Query(ip)
+			ch <- memberResult{index: i, info: info, err: err}
+		}(i, member.Provider)
+	}
+
+	results := make([]*memberResult, len(p.members))
+	done := make([]bool, len(p.members))
+	for pending := len(p.members); pending > 0; {
+		// 等待下一个结果或最早到期的成员超时
+		var timeout <-chan time.Time
+		var timer *time.Timer
+		if deadline, ok := p.nextDeadline(start, done); ok {
+			timer = time.NewTimer(time.Until(deadline))
+			timeout = timer.C
+		}
+
+		select {
+		case result := <-ch:
+			if !done[result.index] {
+				results[result.index] = &result
+				done[result.index] = true
+				pending--
+			}
+		case now := <-timeout:
+			for i, member := range p.members {
+				if !done[i] && member.Timeout > 0 && !now.Before(start.Add(member.Timeout)) {
+					done[i] = true
+					pending--
+				}
+			}
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+
+	return results
+}
+
+// nextDeadline 返回尚未完成的成员中最早的超时时间
+func (p *CompositeProvider) nextDeadline(start time.Time, done []bool) (time.Time, bool) {
+	var deadline time.Time
+	found := false
+	for i, member := range p.members {
+		if done[i] || member.Timeout <= 0 {
+			continue
+		}
+		d := start.Add(member.Timeout)
+		if !found || d.Before(deadline) {
+			deadline = d
+			found = true
+		}
+	}
+	return deadline, found
+}
+
+// merge 按字段优先级合并各成员结果
+func (p *CompositeProvider) merge(ip string, results []*IPInfo) *IPInfo {
+	merged := &IPInfo{
+		IP:      ip,
+		IsValid: true,
+	}
+
+	for field, spec := range mergeFields {
+		for _, i := range p.precedence[field] {
+			info := results[i]
+			if info == nil || !p.members[i].provides(field) || spec.isEmpty(info) {
+				continue
+			}
+			spec.copy(merged, info)
+			break
+		}
+	}
+
+	return merged
+}
+
+// provides 判断成员是否允许提供指定字段
+func (m *CompositeMember) provides(field string) bool {
+	if len(m.Fields) == 0 {
+		return true
+	}
+	for _, f := range m.Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// BatchQuery 批量查询IP地址信息
+func (p *CompositeProvider) BatchQuery(ips []string) ([]*IPInfo, error) {
+	if !p.initialized {
+		return nil, fmt.Errorf("provider not initialized")
+	}
+
+	results := make([]*IPInfo, 0, len(ips))
+	for _, ip := range ips {
+		info, err := p.Query(ip)
+		if err != nil {
+			info = &IPInfo{
+				IP:           ip,
+				IsValid:      false,
+				ErrorMessage: err.Error(),
+			}
+		}
+		results = append(results, info)
+	}
+
+	return results, nil
+}
+
+// Verify 检查所有支持结构校验的成员
+func (p *CompositeProvider) Verify() error {
+	for _, member := range p.members {
+		if verifier, ok := member.Provider.(Verifier); ok {
+			if err := verifier.Verify(); err != nil {
+				return fmt.Errorf("%s: %w", member.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Describe 按成员顺序返回各成员的数据文件
+func (p *CompositeProvider) Describe() []DatabaseFile {
+	var files []DatabaseFile
+	for _, member := range p.members {
+		if describer, ok := member.Provider.(Describer); ok {
+			files = append(files, describer.Describe()...)
+		}
+	}
+	return files
+}
+
+// Close 等待所有成员查询返回后关闭成员提供者
+func (p *CompositeProvider) Close() error {
+	p.inflight.Wait()
+
+	var firstErr error
+	for _, member := range p.members {
+		if err := member.Provider.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	p.initialized = false
+	return firstErr
+}
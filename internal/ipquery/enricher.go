@@ -0,0 +1,15 @@
+package ipquery
+
+// Enricher 在数据源查询结果上补充额外信息的增强器
+// 增强器使用独立的数据文件，文件变化时由服务调用Reload热加载
+type Enricher interface {
+	// Name 返回增强器名称，用于日志
+	Name() string
+	// Enrich 补充IP信息，只填写数据源未提供的字段
+	Enrich(info *IPInfo)
+	// Paths 返回需要监视的数据文件
+	Paths() []string
+	// Reload 重新加载数据文件，失败时继续使用原数据
+	Reload() error
+	Close() error
+}
@@ -0,0 +1,323 @@
+package ipquery
+
+import (
+	"fmt"
+	"io"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 导出格式
+const (
+	ExportFormatIPSet    = "ipset"    // ipset restore格式
+	ExportFormatNFTables = "nftables" // nft -f 脚本
+	ExportFormatIPTables = "iptables" // iptables-restore/ip6tables-restore格式
+	ExportFormatNginx    = "nginx"    // nginx geo块
+	ExportFormatHAProxy  = "haproxy"  // HAProxy map文件
+)
+
+// exportNamePattern 集合名、链名、变量名和表名允许的字符，同时满足各格式的命名规则
+var exportNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,27}$`)
+
+// exportTargetPattern iptables动作允许的字符
+var exportTargetPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]{0,28}$`)
+
+// exportValuePattern nginx geo和HAProxy map中的值允许的字符
+var exportValuePattern = regexp.MustCompile(`^[^\s;{}#"'\\]{1,64}$`)
+
+// ExportOptions 导出选项，零值字段使用默认值
+type ExportOptions struct {
+	Name   string // ipset/nftables集合名、iptables链名、nginx变量名，默认goip
+	Value  string // nginx geo和HAProxy map中命中时的值，默认1
+	Target string // iptables规则的动作，默认DROP
+	Table  string // nftables表名，默认goip
+	Family int    // 4或6只导出对应地址族，0导出全部；iptables为0时导出IPv4
+}
+
+// withDefaults 填充默认值并校验选项
+func (o ExportOptions) withDefaults(format string) (ExportOptions, error) {
+	if o.Name == "" {
+		o.Name = "goip"
+	}
+	if o.Value == "" {
+		o.Value = "1"
+	}
+	if o.Target == "" {
+		o.Target = "DROP"
+	}
+	if o.Table == "" {
+		o.Table = "goip"
+	}
+	if format == ExportFormatIPTables && o.Family == 0 {
+		o.Family = 4
+	}
+
+	for _, name := range []string{o.Name, o.Table} {
+		if !exportNamePattern.MatchString(name) {
+			return o, fmt.Errorf("invalid export name: %q", name)
+		}
+	}
+	if !exportTargetPattern.MatchString(o.Target) {
+		return o, fmt.Errorf("invalid iptables target: %q", o.Target)
+	}
+	if !exportValuePattern.MatchString(o.Value) {
+		return o, fmt.Errorf("invalid export value: %q", o.Value)
+	}
+	if o.Family != 0 && o.Family != 4 && o.Family != 6 {
+		return o, fmt.Errorf("invalid address family: %d", o.Family)
+	}
+	return o, nil
+}
+
+// Exporter 将按地址顺序输入的CIDR写成可直接加载的配置文件
+type Exporter interface {
+	// Begin 写入文件头
+	Begin() error
+	// Add 写入一个CIDR
+	Add(prefix netip.Prefix) error
+	// End 写入文件尾
+	End() error
+}
+
+// exporterFactory 创建导出器
+type exporterFactory func(w io.Writer, opts ExportOptions) Exporter
+
+// exporters 所有导出格式
+var exporters = map[string]exporterFactory{
+	ExportFormatIPSet:    newIPSetExporter,
+	ExportFormatNFTables: newNFTablesExporter,
+	ExportFormatIPTables: newIPTablesExporter,
+	ExportFormatNginx:    newNginxExporter,
+	ExportFormatHAProxy:  newHAProxyExporter,
+}
+
+// ExportFormats 返回支持的导出格式
+func ExportFormats() []string {
+	formats := make([]string, 0, len(exporters))
+	for format := range exporters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// NewExporter 创建指定格式的导出器，只输出opts.Family指定地址族的CIDR
+func NewExporter(format string, w io.Writer, opts ExportOptions) (Exporter, error) {
+	factory, ok := exporters[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported export format %q (available: %s)",
+			format, strings.Join(ExportFormats(), ", "))
+	}
+
+	opts, err := opts.withDefaults(format)
+	if err != nil {
+		return nil, err
+	}
+
+	exporter := factory(w, opts)
+	if opts.Family != 0 {
+		exporter = &familyExporter{Exporter: exporter, family: opts.Family}
+	}
+	return exporter, nil
+}
+
+// ExportRanges 反查满足条件的IP段，合并相邻IP段后写入导出器
+func ExportRanges(provider QueryProvider, filter RangeFilter, exporter Exporter) error {
+	if err := exporter.Begin(); err != nil {
+		return err
+	}
+
+	merger := NewRangeMerger(exporter.Add)
+	err := FindRanges(provider, filter, func(start, end netip.Addr, info *IPInfo) error {
+		return merger.Add(start, end)
+	})
+	if err != nil {
+		return err
+	}
+	if err := merger.Flush(); err != nil {
+		return err
+	}
+	return exporter.End()
+}
+
+// familyExporter 只输出指定地址族的CIDR
+type familyExporter struct {
+	Exporter
+	family int
+}
+
+// Add 跳过其他地址族的CIDR
+func (e *familyExporter) Add(prefix netip.Prefix) error {
+	if prefix.Addr().Is4() != (e.family == 4) {
+		return nil
+	}
+	return e.Exporter.Add(prefix)
+}
+
+// ipsetExporter ipset restore格式，IPv4和IPv6分别使用<name>和<name>6两个hash:net集合
+type ipsetExporter struct {
+	w    io.Writer
+	opts ExportOptions
+}
+
+func newIPSetExporter(w io.Writer, opts ExportOptions) Exporter {
+	return &ipsetExporter{w: w, opts: opts}
+}
+
+// setName 返回CIDR所属地址族的集合名
+func (e *ipsetExporter) setName(is4 bool) string {
+	if is4 {
+		return e.opts.Name
+	}
+	return e.opts.Name + "6"
+}
+
+func (e *ipsetExporter) Begin() error {
+	for _, is4 := range []bool{true, false} {
+		if e.opts.Family == 4 && !is4 || e.opts.Family == 6 && is4 {
+			continue
+		}
+		family := "inet"
+		if !is4 {
+			family = "inet6"
+		}
+		name := e.setName(is4)
+		if _, err := fmt.Fprintf(e.w, "create %s hash:net family %s -exist\nflush %s\n", name, family, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *ipsetExporter) Add(prefix netip.Prefix) error {
+	_, err := fmt.Fprintf(e.w, "add %s %s\n", e.setName(prefix.Addr().Is4()), prefix)
+	return err
+}
+
+func (e *ipsetExporter) End() error {
+	return nil
+}
+
+// nftablesExporter nft -f 脚本，在inet表中创建<name>_v4和<name>_v6两个interval集合
+type nftablesExporter struct {
+	w    io.Writer
+	opts ExportOptions
+}
+
+func newNFTablesExporter(w io.Writer, opts ExportOptions) Exporter {
+	return &nftablesExporter{w: w, opts: opts}
+}
+
+// setName 返回CIDR所属地址族的集合名
+func (e *nftablesExporter) setName(is4 bool) string {
+	if is4 {
+		return e.opts.Name + "_v4"
+	}
+	return e.opts.Name + "_v6"
+}
+
+func (e *nftablesExporter) Begin() error {
+	if _, err := fmt.Fprintf(e.w, "add table inet %s\n", e.opts.Table); err != nil {
+		return err
+	}
+	for _, is4 := range []bool{true, false} {
+		if e.opts.Family == 4 && !is4 || e.opts.Family == 6 && is4 {
+			continue
+		}
+		addrType := "ipv4_addr"
+		if !is4 {
+			addrType = "ipv6_addr"
+		}
+		name := e.setName(is4)
+		if _, err := fmt.Fprintf(e.w, "add set inet %s %s { type %s; flags interval; }\nflush set inet %s %s\n",
+			e.opts.Table, name, addrType, e.opts.Table, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *nftablesExporter) Add(prefix netip.Prefix) error {
+	_, err := fmt.Fprintf(e.w, "add element inet %s %s { %s }\n", e.opts.Table, e.setName(prefix.Addr().Is4()), prefix)
+	return err
+}
+
+func (e *nftablesExporter) End() error {
+	return nil
+}
+
+// iptablesExporter iptables-restore格式，在filter表的<name>链中为每个CIDR添加一条规则
+// 需要使用 --noflush 加载，以免清空其他链
+type iptablesExporter struct {
+	w    io.Writer
+	opts ExportOptions
+}
+
+func newIPTablesExporter(w io.Writer, opts ExportOptions) Exporter {
+	return &iptablesExporter{w: w, opts: opts}
+}
+
+func (e *iptablesExporter) Begin() error {
+	_, err := fmt.Fprintf(e.w, "*filter\n:%s - [0:0]\n-F %s\n", e.opts.Name, e.opts.Name)
+	return err
+}
+
+func (e *iptablesExporter) Add(prefix netip.Prefix) error {
+	_, err := fmt.Fprintf(e.w, "-A %s -s %s -j %s\n", e.opts.Name, prefix, e.opts.Target)
+	return err
+}
+
+func (e *iptablesExporter) End() error {
+	_, err := io.WriteString(e.w, "COMMIT\n")
+	return err
+}
+
+// nginxExporter nginx geo块，命中的地址将变量设为Value，其他地址为0
+type nginxExporter struct {
+	w    io.Writer
+	opts ExportOptions
+}
+
+func newNginxExporter(w io.Writer, opts ExportOptions) Exporter {
+	return &nginxExporter{w: w, opts: opts}
+}
+
+func (e *nginxExporter) Begin() error {
+	_, err := fmt.Fprintf(e.w, "geo $%s {\n    default 0;\n", e.opts.Name)
+	return err
+}
+
+func (e *nginxExporter) Add(prefix netip.Prefix) error {
+	_, err := fmt.Fprintf(e.w, "    %s %s;\n", prefix, e.opts.Value)
+	return err
+}
+
+func (e *nginxExporter) End() error {
+	_, err := io.WriteString(e.w, "}\n")
+	return err
+}
+
+// haproxyExporter HAProxy map文件，配合map_ip使用
+type haproxyExporter struct {
+	w    io.Writer
+	opts ExportOptions
+}
+
+func newHAProxyExporter(w io.Writer, opts ExportOptions) Exporter {
+	return &haproxyExporter{w: w, opts: opts}
+}
+
+func (e *haproxyExporter) Begin() error {
+	return nil
+}
+
+func (e *haproxyExporter) Add(prefix netip.Prefix) error {
+	_, err := fmt.Fprintf(e.w, "%s %s\n", prefix, e.opts.Value)
+	return err
+}
+
+func (e *haproxyExporter) End() error {
+	return nil
+}
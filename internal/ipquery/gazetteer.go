@@ -0,0 +1,275 @@
+package ipquery
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed data/gazetteer.csv
+var defaultGazetteer []byte
+
+// placeSuffixes 匹配地名时忽略的行政区划后缀，按长度从长到短排列
+var placeSuffixes = []string{
+	"维吾尔自治区",
+	"壮族自治区",
+	"回族自治区",
+	"特别行政区",
+	"自治区",
+	"省",
+	"市",
+}
+
+// GazetteerEntry 地名库条目
+type GazetteerEntry struct {
+	CountryCode string
+	Region      string
+	City        string
+	Latitude    float64
+	Longitude   float64
+	Timezone    string
+	PostalCode  string
+	AdminCode   string
+}
+
+// CountryLevel 判断是否为只有国家代码的条目
+func (e *GazetteerEntry) CountryLevel() bool {
+	return e.Region == "" && e.City == ""
+}
+
+// gazetteerKey 地名库索引键，名称已去除后缀
+type gazetteerKey struct {
+	countryCode string
+	region      string
+	city        string
+}
+
+// Gazetteer 离线地名库，将(国家, 省份, 城市)映射到中心坐标、时区和邮编/行政区划代码
+type Gazetteer struct {
+	entries map[gazetteerKey]*GazetteerEntry
+}
+
+// LoadGazetteer 加载内置地名库，path不为空时再合并该文件，相同地名以该文件为准
+func LoadGazetteer(path string) (*Gazetteer, error) {
+	g := &Gazetteer{entries: make(map[gazetteerKey]*GazetteerEntry)}
+	if err := g.parse(bytes.NewReader(defaultGazetteer)); err != nil {
+		return nil, fmt.Errorf("failed to parse default gazetteer: %w", err)
+	}
+
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gazetteer: %w", err)
+		}
+		defer f.Close()
+
+		if err := g.parse(f); err != nil {
+			return nil, fmt.Errorf("failed to parse gazetteer: %w", err)
+		}
+	}
+
+	return g, nil
+}
+
+// parse 解析CSV格式的地名库
+// 第一行为列名，country_code必填，省份和城市为空表示国家或省级条目
+func (g *Gazetteer) parse(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["country_code"]; !ok {
+		return fmt.Errorf("missing country_code column")
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		float := func(name string) (float64, error) {
+			v := field(name)
+			if v == "" {
+				return 0, nil
+			}
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid %s at line %d: %w", name, line, err)
+			}
+			return f, nil
+		}
+
+		entry := &GazetteerEntry{
+			CountryCode: strings.ToUpper(field("country_code")),
+			Region:      field("region"),
+			City:        field("city"),
+			Timezone:    field("timezone"),
+			PostalCode:  field("postal_code"),
+			AdminCode:   field("admin_code"),
+		}
+		if entry.CountryCode == "" {
+			return fmt.Errorf("missing country_code at line %d", line)
+		}
+		if entry.Latitude, err = float("latitude"); err != nil {
+			return err
+		}
+		if entry.Longitude, err = float("longitude"); err != nil {
+			return err
+		}
+
+		g.entries[newGazetteerKey(entry.CountryCode, entry.Region, entry.City)] = entry
+	}
+}
+
+// newGazetteerKey 创建索引键，忽略大小写和行政区划后缀
+func newGazetteerKey(countryCode, region, city string) gazetteerKey {
+	return gazetteerKey{
+		countryCode: strings.ToUpper(strings.TrimSpace(countryCode)),
+		region:      normalizePlaceName(region),
+		city:        normalizePlaceName(city),
+	}
+}
+
+// normalizePlaceName 去除地名的行政区划后缀
+func normalizePlaceName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, suffix := range placeSuffixes {
+		if strings.HasSuffix(name, suffix) && len(name) > len(suffix) {
+			return strings.TrimSuffix(name, suffix)
+		}
+	}
+	return name
+}
+
+// Lookup 按城市、省份、国家的顺序查找最精确的条目
+func (g *Gazetteer) Lookup(countryCode, region, city string) (*GazetteerEntry, bool) {
+	key := newGazetteerKey(countryCode, region, city)
+	if key.countryCode == "" {
+		return nil, false
+	}
+
+	candidates := []gazetteerKey{
+		key,
+		{countryCode: key.countryCode, region: key.region},
+		{countryCode: key.countryCode},
+	}
+	for _, candidate := range candidates {
+		if entry, ok := g.entries[candidate]; ok {
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+// Len 返回条目数
+func (g *Gazetteer) Len() int {
+	return len(g.entries)
+}
+
+// GazetteerEnricher 使用地名库补充经纬度、时区、邮编和行政区划代码
+type GazetteerEnricher struct {
+	path string
+
+	mu        sync.RWMutex
+	gazetteer *Gazetteer
+}
+
+// NewGazetteerEnricher 创建地名库增强器，path为空时只使用内置地名库
+func NewGazetteerEnricher(path string) (*GazetteerEnricher, error) {
+	gazetteer, err := LoadGazetteer(path)
+	if err != nil {
+		return nil, err
+	}
+	return &GazetteerEnricher{path: path, gazetteer: gazetteer}, nil
+}
+
+// Name 返回增强器名称
+func (e *GazetteerEnricher) Name() string {
+	return "gazetteer"
+}
+
+// Paths 返回自定义地名库文件
+func (e *GazetteerEnricher) Paths() []string {
+	if e.path == "" {
+		return nil
+	}
+	return []string{e.path}
+}
+
+// Enrich 补充地理信息，只填写数据源未提供的字段
+func (e *GazetteerEnricher) Enrich(info *IPInfo) {
+	countryCode := info.CountryCode
+	if countryCode == "" {
+		countryCode = getCountryCode(info.Country)
+	}
+
+	e.mu.RLock()
+	entry, ok := e.gazetteer.Lookup(countryCode, info.Region, info.City)
+	e.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	// 国家级条目的坐标是国家中心，与IP的实际位置可能相距上千公里，不用于补充经纬度
+	if info.Latitude == 0 && info.Longitude == 0 && !entry.CountryLevel() {
+		info.Latitude = entry.Latitude
+		info.Longitude = entry.Longitude
+	}
+	if info.Timezone == "" {
+		info.Timezone = entry.Timezone
+	}
+	if info.PostalCode == "" {
+		info.PostalCode = entry.PostalCode
+	}
+	if info.AdminCode == "" {
+		info.AdminCode = entry.AdminCode
+	}
+}
+
+// Reload 重新加载地名库
+func (e *GazetteerEnricher) Reload() error {
+	gazetteer, err := LoadGazetteer(e.path)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.gazetteer = gazetteer
+	e.mu.Unlock()
+	return nil
+}
+
+// Close 释放资源
+func (e *GazetteerEnricher) Close() error {
+	return nil
+}
@@ -1,31 +1,168 @@
 package ipquery
 
 import (
+	"errors"
 	"fmt"
+	"net/netip"
+	"os"
+	"strconv"
 	"strings"
 
-	ip2region "github.com/lionsoul2014/ip2region/binding/golang/xdb"
+	"github.com/ushell/goip/pkg/iso3166"
 )
 
+func init() {
+	registration := ProviderRegistration{
+		Factory: func(opts DatabaseOptions) (QueryProvider, error) {
+			return NewIP2RegionProvider(IP2RegionOptions{
+				Path:      opts.Path,
+				IPv6Path:  opts.IPv6Path,
+				LoadMode:  opts.LoadMode,
+				PoolSize:  opts.PoolSize,
+				CacheSize: opts.CacheSize,
+			})
+		},
+		Paths: func(opts DatabaseOptions) []string {
+			return []string{opts.Path, opts.IPv6Path}
+		},
+	}
+
+	RegisterProvider("ip2region", registration)
+	// local为ip2region的历史名称
+	RegisterProvider("local", registration)
+}
+
+// LoadMode xdb数据库加载模式
+type LoadMode string
+
+// 加载模式定义
+const (
+	// LoadModeAuto 数据库文件不超过CacheSize时整体载入内存，否则缓存向量索引
+	LoadModeAuto LoadMode = "auto"
+	// LoadModeFile 完全基于文件查询，内存占用最小
+	LoadModeFile LoadMode = "file"
+	// LoadModeVector 预加载向量索引（约512KB），减少一次磁盘IO
+	LoadModeVector LoadMode = "vector"
+	// LoadModeMemory 整个数据库载入内存，查询无磁盘IO
+	LoadModeMemory LoadMode = "memory"
+)
+
+// IP2RegionOptions ip2region提供者配置
+type IP2RegionOptions struct {
+	Path      string
+	IPv6Path  string // IPv6段数据文件（ip2region源数据格式），为空时不支持IPv6查询
+	LoadMode  LoadMode
+	PoolSize  int // 已废弃：xdb读取器可被并发使用，不再需要查询器池，该值被忽略
+	CacheSize int // 内存缓存上限(MB)，仅auto模式使用
+}
+
 // IP2RegionProvider 基于ip2region.xdb的真实IP查询提供者
+// xdb数据库读取器是并发安全的，所有查询共享同一个实例。
+// xdb格式仅支持IPv4，IPv6地址由独立的IP段数据库查询
 type IP2RegionProvider struct {
-	db          *ip2region.Searcher
+	db          *xdbDatabase
+	v6db        *RangeDatabase
+	mode        LoadMode
+	path        string
+	v6path      string
 	initialized bool
 }
 
 // NewIP2RegionProvider 创建新的基于ip2region.xdb的查询提供者
-func NewIP2RegionProvider(dbPath string) (*IP2RegionProvider, error) {
-	db, err := ip2region.NewWithFileOnly(dbPath)
+func NewIP2RegionProvider(opts IP2RegionOptions) (*IP2RegionProvider, error) {
+	mode, err := resolveLoadMode(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := openXDB(opts.Path, mode)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load ip2region database: %w", err)
 	}
 
+	var v6db *RangeDatabase
+	if opts.IPv6Path != "" {
+		v6db, err = LoadRangeDatabase(opts.IPv6Path)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to load ipv6 database: %w", err)
+		}
+	}
+
 	return &IP2RegionProvider{
 		db:          db,
+		v6db:        v6db,
+		mode:        mode,
+		path:        opts.Path,
+		v6path:      opts.IPv6Path,
 		initialized: true,
 	}, nil
 }
 
+// resolveLoadMode 解析加载模式，auto模式根据文件大小和CacheSize决定
+func resolveLoadMode(opts IP2RegionOptions) (LoadMode, error) {
+	switch opts.LoadMode {
+	case LoadModeFile, LoadModeVector, LoadModeMemory:
+		return opts.LoadMode, nil
+	case "", LoadModeAuto:
+		stat, err := os.Stat(opts.Path)
+		if err != nil {
+			return "", fmt.Errorf("failed to load ip2region database: %w", err)
+		}
+		if opts.CacheSize > 0 && stat.Size() <= int64(opts.CacheSize)<<20 {
+			return LoadModeMemory, nil
+		}
+		return LoadModeVector, nil
+	default:
+		return "", fmt.Errorf("unknown ip2region load mode: %s", opts.LoadMode)
+	}
+}
+
+// Mode 返回实际使用的加载模式
+func (p *IP2RegionProvider) Mode() LoadMode {
+	return p.mode
+}
+
+// errIPNotFound 数据库中没有包含该IP的段
+var errIPNotFound = errors.New("ip not found")
+
+// search 根据地址族选择数据库查询区域信息及命中的IP范围，未命中时返回errIPNotFound
+// IPv4（包括IPv4映射的IPv6地址）查询xdb数据库，IPv6查询IP段数据库
+func (p *IP2RegionProvider) search(ip string) (string, *IPRange, error) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return "", nil, err
+	}
+	addr = addr.Unmap()
+
+	if addr.Is6() {
+		if p.v6db == nil {
+			return "", nil, fmt.Errorf("ipv6 database not configured")
+		}
+		record, found := p.v6db.Search(addr)
+		if !found {
+			return "", nil, errIPNotFound
+		}
+		return record.Region, NewIPRange(record.Start, record.End), nil
+	}
+
+	seg, err := p.db.Search(addrToUint32(addr))
+	if err != nil {
+		return "", nil, err
+	}
+	if seg == nil {
+		return "", nil, errIPNotFound
+	}
+
+	region, err := p.db.Region(seg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	start, end := p.db.ExpandRange(seg)
+	return region, NewIPRange(uint32ToAddr(start), uint32ToAddr(end)), nil
+}
+
 // Query 查询单个IP地址信息
 func (p *IP2RegionProvider) Query(ip string) (*IPInfo, error) {
 	if !p.initialized {
@@ -45,7 +182,7 @@ func (p *IP2RegionProvider) Query(ip string) (*IPInfo, error) {
 			IP:          ip,
 			IsValid:     true,
 			Country:     "局域网",
-			CountryCode: "LAN",
+			CountryCode: "", // 局域网不是ISO 3166-1国家，由address_type标注为private
 			Region:      "局域网",
 			City:        "局域网",
 			ISP:         "局域网",
@@ -57,7 +194,14 @@ func (p *IP2RegionProvider) Query(ip string) (*IPInfo, error) {
 	}
 
 	// 使用ip2region查询真实数据
-	info, err := p.db.SearchByStr(ip)
+	info, ipRange, err := p.search(ip)
+	if err == errIPNotFound {
+		return &IPInfo{
+			IP:           ip,
+			IsValid:      false,
+			ErrorMessage: "数据库中未找到该IP",
+		}, nil
+	}
 	if err != nil {
 		return &IPInfo{
 			IP:           ip,
@@ -66,9 +210,15 @@ func (p *IP2RegionProvider) Query(ip string) (*IPInfo, error) {
 		}, nil
 	}
 
-	// 解析ip2region返回的数据格式
-	// 格式: 国家|区域|省份|城市|ISP
-	parts := strings.Split(info, "|")
+	result := parseRegion(ip, info)
+	result.Range = ipRange
+	return result, nil
+}
+
+// parseRegion 解析ip2region返回的区域数据
+// 格式: 国家|区域|省份|城市|ISP
+func parseRegion(ip, region string) *IPInfo {
+	parts := strings.Split(region, "|")
 
 	// 处理空值
 	for i := range parts {
@@ -83,27 +233,21 @@ func (p *IP2RegionProvider) Query(ip string) (*IPInfo, error) {
 	}
 
 	country := parts[0]
-	region := parts[2]
-	city := parts[3]
-	isp := parts[4]
-
-	// 设置国家代码
-	countryCode := getCountryCode(country)
 
 	return &IPInfo{
 		IP:          ip,
 		Country:     country,
-		CountryCode: countryCode,
-		Region:      region,
-		City:        city,
+		CountryCode: getCountryCode(country),
+		Region:      parts[2],
+		City:        parts[3],
 		District:    "", // ip2region不提供区县信息
-		ISP:         isp,
+		ISP:         parts[4],
 		Latitude:    0,  // ip2region不提供经纬度
 		Longitude:   0,  // ip2region不提供经纬度
 		Timezone:    "", // ip2region不提供时区
 		PostalCode:  "", // ip2region不提供邮政编码
 		IsValid:     true,
-	}, nil
+	}
 }
 
 // BatchQuery 批量查询IP地址信息
@@ -128,35 +272,77 @@ func (p *IP2RegionProvider) BatchQuery(ips []string) ([]*IPInfo, error) {
 	return results, nil
 }
 
-// Close 关闭提供者，释放资源
+// WalkRanges 按地址顺序遍历与[start, end]重叠的IP段
+// IPv4段来自xdb数据库，IPv6段来自IP段数据库，未配置IPv6数据库时IPv6范围没有结果
+func (p *IP2RegionProvider) WalkRanges(start, end netip.Addr, fn RangeFunc) error {
+	if !p.initialized {
+		return fmt.Errorf("provider not initialized")
+	}
+
+	start, end = start.Unmap(), end.Unmap()
+	if start.BitLen() != end.BitLen() || end.Less(start) {
+		return fmt.Errorf("invalid range: %s-%s", start, end)
+	}
+
+	if start.Is6() {
+		if p.v6db == nil {
+			return nil
+		}
+		return p.v6db.Walk(start, end, func(record *RangeRecord) error {
+			return fn(record.Start, record.End, parseRegion(record.Start.String(), record.Region))
+		})
+	}
+
+	return p.db.Walk(addrToUint32(start), addrToUint32(end), func(s, e uint32, region string) error {
+		rangeStart := uint32ToAddr(s)
+		return fn(rangeStart, uint32ToAddr(e), parseRegion(rangeStart.String(), region))
+	})
+}
+
+// Verify 检查xdb数据库结构，配置了IPv6数据库时要求其非空
+func (p *IP2RegionProvider) Verify() error {
+	if err := p.db.Verify(); err != nil {
+		return err
+	}
+	if p.v6db != nil && p.v6db.Len() == 0 {
+		return fmt.Errorf("ipv6 database is empty")
+	}
+	return nil
+}
+
+// Describe 返回xdb数据库及IPv6数据库的格式、构建时间和IP段数
+func (p *IP2RegionProvider) Describe() []DatabaseFile {
+	files := []DatabaseFile{{
+		Path:      p.path,
+		Format:    "ip2region-xdb",
+		Version:   strconv.Itoa(int(p.db.header.Version)),
+		BuildTime: unixTime(int64(p.db.header.CreatedAt)),
+		Records:   int(p.db.segmentCount()),
+	}}
+	if p.v6db != nil {
+		files = append(files, DatabaseFile{
+			Path:    p.v6path,
+			Format:  "ip2region-ranges",
+			Records: p.v6db.Len(),
+		})
+	}
+	return files
+}
+
+// Close 关闭提供者，释放数据库文件
 func (p *IP2RegionProvider) Close() error {
-	if p.db != nil {
-		p.db.Close()
+	if !p.initialized {
+		return nil
 	}
 	p.initialized = false
-	return nil
+
+	return p.db.Close()
 }
 
-// getCountryCode 根据国家名称获取国家代码
+// getCountryCode 根据国家名称获取ISO 3166-1两位字母代码，未知国家返回空字符串
 func getCountryCode(country string) string {
-	switch country {
-	case "中国":
-		return "CN"
-	case "美国":
-		return "US"
-	case "日本":
-		return "JP"
-	case "韩国":
-		return "KR"
-	case "德国":
-		return "DE"
-	case "英国":
-		return "GB"
-	case "法国":
-		return "FR"
-	case "加拿大":
-		return "CA"
-	default:
-		return "未知" // 未知国家
+	if c, ok := iso3166.ByChineseName(country); ok {
+		return c.Alpha2
 	}
+	return ""
 }
@@ -7,19 +7,28 @@ import (
 
 // IPInfo IP信息结构体
 type IPInfo struct {
-	IP           string  `json:"ip"`
-	Country      string  `json:"country"`
-	CountryCode  string  `json:"country_code"`
-	Region       string  `json:"region"`
-	City         string  `json:"city"`
-	District     string  `json:"district"`
-	ISP          string  `json:"isp"`
-	Latitude     float64 `json:"latitude"`
-	Longitude    float64 `json:"longitude"`
-	Timezone     string  `json:"timezone"`
-	PostalCode   string  `json:"postal_code"`
-	IsValid      bool    `json:"is_valid"`
-	ErrorMessage string  `json:"error_message,omitempty"`
+	IP             string      `json:"ip"`
+	Country        string      `json:"country"`
+	CountryCode    string      `json:"country_code"`
+	Region         string      `json:"region"`
+	City           string      `json:"city"`
+	District       string      `json:"district"`
+	ISP            string      `json:"isp"`
+	Latitude       float64     `json:"latitude"`
+	Longitude      float64     `json:"longitude"`
+	Timezone       string      `json:"timezone"`
+	PostalCode     string      `json:"postal_code"`
+	AdminCode      string      `json:"admin_code,omitempty"` // 行政区划代码，中国为GB/T 2260代码
+	Range          *IPRange    `json:"range,omitempty"`      // 命中的IP范围
+	AddressType    AddressType `json:"address_type,omitempty"`
+	Scope          string      `json:"scope,omitempty"` // 特殊用途地址块名称，如shared_address_space、6to4
+	Tags           []string    `json:"tags,omitempty"`  // 覆盖表中的自定义标签
+	ASN            uint32      `json:"asn,omitempty"`
+	ASOrganization string      `json:"as_organization,omitempty"`
+	ASPrefix       string      `json:"as_prefix,omitempty"` // 包含该地址的宣告网段
+	Threat         *ThreatInfo `json:"threat,omitempty"`    // 配置了信誉列表时返回
+	IsValid        bool        `json:"is_valid"`
+	ErrorMessage   string      `json:"error_message,omitempty"`
 }
 
 // QueryProvider IP查询提供者接口
@@ -50,39 +59,19 @@ func ValidateIP(ip string) bool {
 	return false
 }
 
-// IsPrivateIP 检查是否为私有IP
+// IsPrivateIP 检查是否为私有IP（私有、环回及链路本地地址）
 func IsPrivateIP(ip string) bool {
-	ipAddr := net.ParseIP(ip)
-	if ipAddr == nil {
+	class, ok := ClassifyIP(ip)
+	if !ok {
 		return false
 	}
 
-	// IPv4私有地址范围
-	privateIPv4 := []string{
-		"10.0.0.0/8",
-		"172.16.0.0/12",
-		"192.168.0.0/16",
-		"127.0.0.0/8",
-		"169.254.0.0/16",
-	}
-
-	// IPv6私有地址范围
-	privateIPv6 := []string{
-		"fc00::/7",
-		"fe80::/10",
-		"::1/128",
-	}
-
-	allPrivate := append(privateIPv4, privateIPv6...)
-
-	for _, cidr := range allPrivate {
-		_, subnet, _ := net.ParseCIDR(cidr)
-		if subnet.Contains(ipAddr) {
-			return true
-		}
+	switch class.Type {
+	case AddressTypePrivate, AddressTypeLoopback, AddressTypeLinkLocal:
+		return true
+	default:
+		return false
 	}
-
-	return false
 }
 
 // IsPublicIP 检查是否为公网IP
@@ -0,0 +1,64 @@
+package ipquery
+
+import (
+	"github.com/ushell/goip/pkg/i18n"
+)
+
+// Localize 返回指定语言的IP信息副本，不修改原对象（原对象可能来自缓存）
+// 数据源统一返回中文，默认语言下直接返回原对象
+func (info *IPInfo) Localize(lang i18n.Lang) *IPInfo {
+	if info == nil || lang == i18n.DefaultLang {
+		return info
+	}
+
+	localized := *info
+	localized.Country = i18n.Country(info.Country, info.CountryCode, lang)
+	localized.Region = i18n.Region(info.Region, lang)
+	localized.City = i18n.City(info.City, lang)
+	localized.District = i18n.Place(info.District, lang)
+	localized.ISP = i18n.ISP(info.ISP, lang)
+	localized.ErrorMessage = i18n.Message(info.ErrorMessage, lang)
+
+	return &localized
+}
+
+// Localize 返回指定语言的网段查询结果副本，不修改原对象
+func (r *CIDRReport) Localize(lang i18n.Lang) *CIDRReport {
+	if r == nil || lang == i18n.DefaultLang {
+		return r
+	}
+
+	localized := *r
+	localized.Ranges = make([]*IPInfo, 0, len(r.Ranges))
+	for _, info := range r.Ranges {
+		localized.Ranges = append(localized.Ranges, info.Localize(lang))
+	}
+
+	localized.Countries = make([]CIDRSummaryItem, 0, len(r.Countries))
+	for _, item := range r.Countries {
+		item.Name = i18n.Country(item.Name, item.Code, lang)
+		localized.Countries = append(localized.Countries, item)
+	}
+
+	localized.ISPs = make([]CIDRSummaryItem, 0, len(r.ISPs))
+	for _, item := range r.ISPs {
+		item.Name = i18n.ISP(item.Name, lang)
+		localized.ISPs = append(localized.ISPs, item)
+	}
+
+	return &localized
+}
+
+// Localize 返回指定语言的反查结果副本，不修改原对象
+func (p *RangePage) Localize(lang i18n.Lang) *RangePage {
+	if p == nil || lang == i18n.DefaultLang {
+		return p
+	}
+
+	localized := *p
+	localized.Ranges = make([]*IPInfo, 0, len(p.Ranges))
+	for _, info := range p.Ranges {
+		localized.Ranges = append(localized.Ranges, info.Localize(lang))
+	}
+	return &localized
+}
@@ -0,0 +1,271 @@
+package ipquery
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+
+	"github.com/oschwald/geoip2-golang"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+func init() {
+	RegisterProvider("mmdb", ProviderRegistration{
+		Factory: func(opts DatabaseOptions) (QueryProvider, error) {
+			return NewMMDBProvider(opts.MMDB)
+		},
+		Paths: func(opts DatabaseOptions) []string {
+			return []string{opts.MMDB.CityPath, opts.MMDB.CountryPath, opts.MMDB.ASNPath}
+		},
+	})
+}
+
+// MMDBOptions MaxMind MMDB提供者配置
+type MMDBOptions struct {
+	CityPath    string // GeoLite2-City.mmdb，提供城市、经纬度、时区和邮编
+	CountryPath string // GeoLite2-Country.mmdb，未配置CityPath时用于国家查询
+	ASNPath     string // GeoLite2-ASN.mmdb，提供运营商信息
+	Language    string // 名称语言，如zh-CN、en，缺失时回退到英文
+}
+
+// MMDBProvider 基于MaxMind GeoLite2/GeoIP2 MMDB文件的查询提供者
+// 直接使用maxminddb.Reader解码geoip2记录，以便同时获取命中的网段；
+// maxminddb.Reader是并发安全的，可直接在多个goroutine中共享
+type MMDBProvider struct {
+	city        *maxminddb.Reader
+	country     *maxminddb.Reader
+	asn         *maxminddb.Reader
+	paths       map[*maxminddb.Reader]string
+	language    string
+	initialized bool
+}
+
+// NewMMDBProvider 创建新的MMDB查询提供者
+func NewMMDBProvider(opts MMDBOptions) (*MMDBProvider, error) {
+	if opts.CityPath == "" && opts.CountryPath == "" {
+		return nil, fmt.Errorf("mmdb city or country database path is required")
+	}
+
+	p := &MMDBProvider{
+		paths:    make(map[*maxminddb.Reader]string),
+		language: opts.Language,
+	}
+	if p.language == "" {
+		p.language = "zh-CN"
+	}
+
+	var err error
+	if opts.CityPath != "" {
+		if p.city, err = maxminddb.Open(opts.CityPath); err != nil {
+			return nil, fmt.Errorf("failed to load mmdb city database: %w", err)
+		}
+		p.paths[p.city] = opts.CityPath
+	} else {
+		if p.country, err = maxminddb.Open(opts.CountryPath); err != nil {
+			return nil, fmt.Errorf("failed to load mmdb country database: %w", err)
+		}
+		p.paths[p.country] = opts.CountryPath
+	}
+
+	if opts.ASNPath != "" {
+		if p.asn, err = maxminddb.Open(opts.ASNPath); err != nil {
+			p.Close()
+			return nil, fmt.Errorf("failed to load mmdb asn database: %w", err)
+		}
+		p.paths[p.asn] = opts.ASNPath
+	}
+
+	p.initialized = true
+	return p, nil
+}
+
+// Query 查询单个IP地址信息
+func (p *MMDBProvider) Query(ip string) (*IPInfo, error) {
+	if !p.initialized {
+		return nil, fmt.Errorf("provider not initialized")
+	}
+
+	if !ValidateIP(ip) {
+		return &IPInfo{
+			IP:           ip,
+			IsValid:      false,
+			ErrorMessage: "无效的IP地址格式",
+		}, nil
+	}
+
+	if IsPrivateIP(ip) {
+		return &IPInfo{
+			IP:          ip,
+			IsValid:     true,
+			Country:     "局域网",
+			CountryCode: "", // 局域网不是ISO 3166-1国家，由address_type标注为private
+			Region:      "局域网",
+			City:        "局域网",
+			ISP:         "局域网",
+			Latitude:    0,
+			Longitude:   0,
+			Timezone:    "UTC",
+			PostalCode:  "000000",
+		}, nil
+	}
+
+	addr := net.ParseIP(ip)
+	info := &IPInfo{
+		IP:      ip,
+		IsValid: true,
+	}
+
+	if p.city != nil {
+		var record geoip2.City
+		network, _, err := p.city.LookupNetwork(addr, &record)
+		if err != nil {
+			return &IPInfo{
+				IP:           ip,
+				IsValid:      false,
+				ErrorMessage: fmt.Sprintf("IP查询失败: %v", err),
+			}, nil
+		}
+
+		info.Country = p.name(record.Country.Names)
+		info.CountryCode = record.Country.IsoCode
+		if len(record.Subdivisions) > 0 {
+			info.Region = p.name(record.Subdivisions[0].Names)
+		}
+		info.City = p.name(record.City.Names)
+		info.Latitude = record.Location.Latitude
+		info.Longitude = record.Location.Longitude
+		info.Timezone = record.Location.TimeZone
+		info.PostalCode = record.Postal.Code
+		info.Range = networkRange(network)
+	} else {
+		var record geoip2.Country
+		network, _, err := p.country.LookupNetwork(addr, &record)
+		if err != nil {
+			return &IPInfo{
+				IP:           ip,
+				IsValid:      false,
+				ErrorMessage: fmt.Sprintf("IP查询失败: %v", err),
+			}, nil
+		}
+
+		info.Country = p.name(record.Country.Names)
+		info.CountryCode = record.Country.IsoCode
+		info.Range = networkRange(network)
+	}
+
+	if p.asn != nil {
+		// ASN库查询失败不影响地理位置结果
+		var record geoip2.ASN
+		if network, _, err := p.asn.LookupNetwork(addr, &record); err == nil {
+			info.ISP = record.AutonomousSystemOrganization
+			info.ASN = uint32(record.AutonomousSystemNumber)
+			info.ASOrganization = record.AutonomousSystemOrganization
+			if prefix, ok := networkPrefix(network); ok && info.ASN != 0 {
+				info.ASPrefix = prefix.String()
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// BatchQuery 批量查询IP地址信息
+func (p *MMDBProvider) BatchQuery(ips []string) ([]*IPInfo, error) {
+	if !p.initialized {
+		return nil, fmt.Errorf("provider not initialized")
+	}
+
+	results := make([]*IPInfo, 0, len(ips))
+	for _, ip := range ips {
+		info, err := p.Query(ip)
+		if err != nil {
+			info = &IPInfo{
+				IP:           ip,
+				IsValid:      false,
+				ErrorMessage: err.Error(),
+			}
+		}
+		results = append(results, info)
+	}
+
+	return results, nil
+}
+
+// Verify 完整检查所有已打开的MMDB文件的元数据、搜索树和数据区
+func (p *MMDBProvider) Verify() error {
+	for _, reader := range []*maxminddb.Reader{p.city, p.country, p.asn} {
+		if reader == nil {
+			continue
+		}
+		if err := reader.Verify(); err != nil {
+			return fmt.Errorf("invalid %s database: %w", reader.Metadata.DatabaseType, err)
+		}
+	}
+	return nil
+}
+
+// Describe 返回已打开的MMDB文件的数据库类型、格式版本和构建时间
+// MMDB以搜索树存储，元数据中没有IP段数量
+func (p *MMDBProvider) Describe() []DatabaseFile {
+	var files []DatabaseFile
+	for _, reader := range []*maxminddb.Reader{p.city, p.country, p.asn} {
+		if reader == nil {
+			continue
+		}
+		meta := reader.Metadata
+		files = append(files, DatabaseFile{
+			Path:      p.paths[reader],
+			Format:    meta.DatabaseType,
+			Version:   fmt.Sprintf("%d.%d", meta.BinaryFormatMajorVersion, meta.BinaryFormatMinorVersion),
+			BuildTime: unixTime(int64(meta.BuildEpoch)),
+		})
+	}
+	return files
+}
+
+// Close 关闭提供者，释放资源
+func (p *MMDBProvider) Close() error {
+	for _, reader := range []*maxminddb.Reader{p.city, p.country, p.asn} {
+		if reader != nil {
+			reader.Close()
+		}
+	}
+	p.initialized = false
+	return nil
+}
+
+// name 按配置语言选择名称，缺失时回退到英文
+func (p *MMDBProvider) name(names map[string]string) string {
+	if name, ok := names[p.language]; ok {
+		return name
+	}
+	return names["en"]
+}
+
+// networkRange 将MMDB返回的网段转换为IP范围
+func networkRange(network *net.IPNet) *IPRange {
+	prefix, ok := networkPrefix(network)
+	if !ok {
+		return nil
+	}
+	return NewIPRange(prefix.Addr(), LastAddr(prefix))
+}
+
+// networkPrefix 将MMDB返回的网段转换为netip.Prefix
+func networkPrefix(network *net.IPNet) (netip.Prefix, bool) {
+	if network == nil {
+		return netip.Prefix{}, false
+	}
+
+	ones, bits := network.Mask.Size()
+	ip := network.IP
+	if bits == 32 {
+		ip = ip.To4()
+	}
+
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok || addr.BitLen() != bits {
+		return netip.Prefix{}, false
+	}
+	return netip.PrefixFrom(addr, ones).Masked(), true
+}
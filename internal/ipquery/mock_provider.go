@@ -6,6 +6,14 @@ import (
 	"time"
 )
 
+func init() {
+	RegisterProvider("mock", ProviderRegistration{
+		Factory: func(opts DatabaseOptions) (QueryProvider, error) {
+			return NewMockProvider(), nil
+		},
+	})
+}
+
 // MockProvider 模拟IP查询提供者
 type MockProvider struct {
 	initialized bool
@@ -37,7 +45,7 @@ func (m *MockProvider) Query(ip string) (*IPInfo, error) {
 			IP:          ip,
 			IsValid:     true,
 			Country:     "局域网",
-			CountryCode: "LAN",
+			CountryCode: "", // 局域网不是ISO 3166-1国家，由address_type标注为private
 			Region:      "局域网",
 			City:        "局域网",
 			ISP:         "局域网",
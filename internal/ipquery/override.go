@@ -0,0 +1,403 @@
+package ipquery
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Override 用户自定义的CIDR覆盖记录
+type Override struct {
+	CIDR        string   `yaml:"cidr"`
+	Country     string   `yaml:"country"`
+	CountryCode string   `yaml:"country_code"`
+	Region      string   `yaml:"region"`
+	City        string   `yaml:"city"`
+	District    string   `yaml:"district"`
+	ISP         string   `yaml:"isp"`
+	Latitude    float64  `yaml:"latitude"`
+	Longitude   float64  `yaml:"longitude"`
+	Timezone    string   `yaml:"timezone"`
+	PostalCode  string   `yaml:"postal_code"`
+	Tags        []string `yaml:"tags"`
+
+	prefix netip.Prefix
+}
+
+// SetsLocation 判断覆盖记录是否设置了位置字段
+// 设置了位置字段的记录整体替换数据库结果，只设置ISP或标签的记录是叠加在结果上的注解
+func (o *Override) SetsLocation() bool {
+	return o.Country != "" || o.CountryCode != "" || o.Region != "" || o.City != "" ||
+		o.District != "" || o.Latitude != 0 || o.Longitude != 0 || o.Timezone != "" || o.PostalCode != ""
+}
+
+// Apply 根据覆盖记录生成IP信息，未设置国家代码时根据国家名称推导
+func (o *Override) Apply(ip string) *IPInfo {
+	countryCode := o.CountryCode
+	if countryCode == "" {
+		countryCode = getCountryCode(o.Country)
+	}
+
+	return &IPInfo{
+		IP:          ip,
+		Country:     o.Country,
+		CountryCode: countryCode,
+		Region:      o.Region,
+		City:        o.City,
+		District:    o.District,
+		ISP:         o.ISP,
+		Latitude:    o.Latitude,
+		Longitude:   o.Longitude,
+		Timezone:    o.Timezone,
+		PostalCode:  o.PostalCode,
+		Range:       NewIPRange(o.prefix.Addr(), LastAddr(o.prefix)),
+		Tags:        append([]string(nil), o.Tags...),
+		IsValid:     true,
+	}
+}
+
+// Annotate 复制info并叠加覆盖记录中非空的ISP和标签，其余字段保持原值
+func (o *Override) Annotate(info *IPInfo) *IPInfo {
+	annotated := *info
+	if o.ISP != "" {
+		annotated.ISP = o.ISP
+	}
+	if len(o.Tags) > 0 {
+		annotated.Tags = append(append([]string(nil), info.Tags...), o.Tags...)
+	}
+	return &annotated
+}
+
+// OverrideTable CIDR覆盖表，按最长前缀匹配
+// 记录按前缀长度分组，查找时从最长的前缀开始逐级掩码后查表
+type OverrideTable struct {
+	byPrefix map[netip.Prefix]*Override
+	bits     []int // 存在记录的前缀长度，从长到短
+}
+
+// NewOverrideTable 根据覆盖记录创建覆盖表，重复的CIDR视为错误
+func NewOverrideTable(overrides []Override) (*OverrideTable, error) {
+	t := &OverrideTable{byPrefix: make(map[netip.Prefix]*Override, len(overrides))}
+
+	seenBits := make(map[int]bool)
+	for i := range overrides {
+		o := &overrides[i]
+		prefix, err := parseCIDROrAddr(o.CIDR)
+		if err != nil {
+			return nil, fmt.Errorf("invalid override #%d: %w", i+1, err)
+		}
+		if _, ok := t.byPrefix[prefix]; ok {
+			return nil, fmt.Errorf("duplicate override cidr: %s", prefix)
+		}
+
+		o.prefix = prefix
+		t.byPrefix[prefix] = o
+		if !seenBits[prefix.Bits()] {
+			seenBits[prefix.Bits()] = true
+			t.bits = append(t.bits, prefix.Bits())
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(t.bits)))
+	return t, nil
+}
+
+// LoadOverrideTable 从YAML或CSV文件加载覆盖表，格式由扩展名决定
+func LoadOverrideTable(path string) (*OverrideTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open override file: %w", err)
+	}
+	defer f.Close()
+
+	var overrides []Override
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		overrides, err = parseOverrideYAML(f)
+	case ".csv":
+		overrides, err = parseOverrideCSV(f)
+	default:
+		return nil, fmt.Errorf("unsupported override file format: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse override file: %w", err)
+	}
+
+	return NewOverrideTable(overrides)
+}
+
+// parseOverrideYAML 解析YAML格式的覆盖记录列表
+func parseOverrideYAML(r io.Reader) ([]Override, error) {
+	var overrides []Override
+	if err := yaml.NewDecoder(r).Decode(&overrides); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// parseOverrideCSV 解析CSV格式的覆盖记录
+// 第一行为列名，cidr列必填，其余列与YAML字段同名，tags列中多个标签以分号分隔
+func parseOverrideCSV(r io.Reader) ([]Override, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["cidr"]; !ok {
+		return nil, fmt.Errorf("missing cidr column")
+	}
+
+	var overrides []Override
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return overrides, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		float := func(name string) (float64, error) {
+			v := field(name)
+			if v == "" {
+				return 0, nil
+			}
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid %s at line %d: %w", name, line, err)
+			}
+			return f, nil
+		}
+
+		o := Override{
+			CIDR:        field("cidr"),
+			Country:     field("country"),
+			CountryCode: field("country_code"),
+			Region:      field("region"),
+			City:        field("city"),
+			District:    field("district"),
+			ISP:         field("isp"),
+			Timezone:    field("timezone"),
+			PostalCode:  field("postal_code"),
+		}
+		if o.Latitude, err = float("latitude"); err != nil {
+			return nil, err
+		}
+		if o.Longitude, err = float("longitude"); err != nil {
+			return nil, err
+		}
+		for _, tag := range strings.Split(field("tags"), ";") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				o.Tags = append(o.Tags, tag)
+			}
+		}
+
+		overrides = append(overrides, o)
+	}
+}
+
+// Lookup 按最长前缀匹配查找覆盖记录
+func (t *OverrideTable) Lookup(addr netip.Addr) (*Override, bool) {
+	addr = addr.Unmap()
+	for _, bits := range t.bits {
+		if bits > addr.BitLen() {
+			continue
+		}
+		prefix, err := addr.Prefix(bits)
+		if err != nil {
+			continue
+		}
+		if o, ok := t.byPrefix[prefix]; ok {
+			return o, true
+		}
+	}
+	return nil, false
+}
+
+// Matches 返回包含该地址的所有覆盖记录，前缀从长到短
+func (t *OverrideTable) Matches(addr netip.Addr) []*Override {
+	addr = addr.Unmap()
+	var matches []*Override
+	for _, bits := range t.bits {
+		if bits > addr.BitLen() {
+			continue
+		}
+		prefix, err := addr.Prefix(bits)
+		if err != nil {
+			continue
+		}
+		if o, ok := t.byPrefix[prefix]; ok {
+			matches = append(matches, o)
+		}
+	}
+	return matches
+}
+
+// Len 返回覆盖记录数
+func (t *OverrideTable) Len() int {
+	return len(t.byPrefix)
+}
+
+// OverrideProvider 在数据库查询之前应用CIDR覆盖表的查询提供者
+// 命中设置了位置字段的覆盖记录的地址直接返回覆盖信息，不再查询底层数据源；
+// 只设置ISP或标签的记录叠加在底层数据源的结果上
+type OverrideProvider struct {
+	provider QueryProvider
+	path     string
+
+	mu    sync.RWMutex
+	table *OverrideTable
+}
+
+// NewOverrideProvider 创建覆盖提供者，path为空时不应用任何覆盖
+func NewOverrideProvider(provider QueryProvider, path string) (*OverrideProvider, error) {
+	p := &OverrideProvider{
+		provider: provider,
+		path:     path,
+		table:    &OverrideTable{},
+	}
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Reload 重新加载覆盖文件，加载失败时继续使用原覆盖表
+func (p *OverrideProvider) Reload() error {
+	if p.path == "" {
+		return nil
+	}
+
+	table, err := LoadOverrideTable(p.path)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.table = table
+	p.mu.Unlock()
+	return nil
+}
+
+// Path 返回覆盖文件路径
+func (p *OverrideProvider) Path() string {
+	return p.path
+}
+
+// Len 返回当前覆盖记录数
+func (p *OverrideProvider) Len() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.table.Len()
+}
+
+// lookup 在当前覆盖表中查找IP地址，返回整体替换结果的记录以及需要叠加的注解记录
+// 从最长前缀开始，第一条设置了位置字段的记录作为结果，比它更长的注解记录按从短到长的顺序叠加；
+// 没有设置位置字段的记录时base为nil，注解叠加在数据库结果上
+func (p *OverrideProvider) lookup(ip string) (base *Override, annotations []*Override) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return nil, nil
+	}
+
+	p.mu.RLock()
+	matches := p.table.Matches(addr)
+	p.mu.RUnlock()
+
+	for _, o := range matches {
+		if o.SetsLocation() {
+			base = o
+			break
+		}
+		annotations = append([]*Override{o}, annotations...)
+	}
+	return base, annotations
+}
+
+// annotate 在查询结果上依次叠加注解记录，查询失败的结果保持不变
+func annotate(info *IPInfo, annotations []*Override) *IPInfo {
+	if !info.IsValid {
+		return info
+	}
+	for _, o := range annotations {
+		info = o.Annotate(info)
+	}
+	return info
+}
+
+// Query 查询单个IP地址信息
+func (p *OverrideProvider) Query(ip string) (*IPInfo, error) {
+	base, annotations := p.lookup(ip)
+	if base != nil {
+		return annotate(base.Apply(ip), annotations), nil
+	}
+
+	info, err := p.provider.Query(ip)
+	if err != nil {
+		return nil, err
+	}
+	return annotate(info, annotations), nil
+}
+
+// BatchQuery 批量查询IP地址信息，未被覆盖记录整体替换的地址合并为一次底层批量查询
+func (p *OverrideProvider) BatchQuery(ips []string) ([]*IPInfo, error) {
+	results := make([]*IPInfo, len(ips))
+	var missIPs []string
+	var missIndex []int
+	var missAnnotations [][]*Override
+	for i, ip := range ips {
+		base, annotations := p.lookup(ip)
+		if base != nil {
+			results[i] = annotate(base.Apply(ip), annotations)
+			continue
+		}
+		missIPs = append(missIPs, ip)
+		missIndex = append(missIndex, i)
+		missAnnotations = append(missAnnotations, annotations)
+	}
+
+	if len(missIPs) > 0 {
+		infos, err := p.provider.BatchQuery(missIPs)
+		if err != nil {
+			return nil, err
+		}
+		for i, info := range infos {
+			results[missIndex[i]] = annotate(info, missAnnotations[i])
+		}
+	}
+
+	return results, nil
+}
+
+// Close 关闭底层提供者
+func (p *OverrideProvider) Close() error {
+	return p.provider.Close()
+}
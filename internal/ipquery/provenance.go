@@ -0,0 +1,99 @@
+package ipquery
+
+import (
+	"os"
+	"time"
+)
+
+// DatabaseFile 数据文件的来源信息
+type DatabaseFile struct {
+	Path      string     `json:"path"`
+	Format    string     `json:"format,omitempty"`     // 文件格式，如ip2region-xdb、GeoLite2-City
+	Version   string     `json:"version,omitempty"`    // 文件格式版本
+	BuildTime *time.Time `json:"build_time,omitempty"` // 数据文件中记录的构建时间
+	Records   int        `json:"records,omitempty"`    // IP段记录数
+	Size      int64      `json:"size"`
+	SHA256    string     `json:"sha256"`
+}
+
+// DatabaseInfo 当前使用的IP数据库的来源信息，用于确认查询结果来自哪个数据文件
+type DatabaseInfo struct {
+	Type     string         `json:"type"` // ip_database.type
+	Files    []DatabaseFile `json:"files"`
+	LoadedAt time.Time      `json:"loaded_at"`
+}
+
+// Checksums 返回各数据文件的SHA-256
+func (d *DatabaseInfo) Checksums() []string {
+	sums := make([]string, 0, len(d.Files))
+	for _, f := range d.Files {
+		sums = append(sums, f.SHA256)
+	}
+	return sums
+}
+
+// BuildTime 返回各数据文件中最新的构建时间，均未记录时返回零值
+func (d *DatabaseInfo) BuildTime() time.Time {
+	var latest time.Time
+	for _, f := range d.Files {
+		if f.BuildTime != nil && f.BuildTime.After(latest) {
+			latest = *f.BuildTime
+		}
+	}
+	return latest
+}
+
+// Records 返回各数据文件的IP段记录总数
+func (d *DatabaseInfo) Records() int {
+	total := 0
+	for _, f := range d.Files {
+		total += f.Records
+	}
+	return total
+}
+
+// Describer 能够描述所用数据文件的查询提供者
+type Describer interface {
+	// Describe 返回各数据文件的路径、格式、版本、构建时间和记录数，大小和校验和由DescribeDatabase填充
+	Describe() []DatabaseFile
+}
+
+// DescribeDatabase 在提供者加载完成后记录其数据文件的来源信息
+// 提供者未实现Describer时只记录选项中的数据文件路径
+func DescribeDatabase(opts DatabaseOptions, provider QueryProvider) *DatabaseInfo {
+	info := &DatabaseInfo{
+		Type:     opts.Type,
+		Files:    []DatabaseFile{},
+		LoadedAt: time.Now(),
+	}
+
+	if describer, ok := provider.(Describer); ok {
+		info.Files = append(info.Files, describer.Describe()...)
+	} else if paths, err := DatabasePaths(opts); err == nil {
+		for _, path := range paths {
+			if path != "" {
+				info.Files = append(info.Files, DatabaseFile{Path: path})
+			}
+		}
+	}
+
+	for i := range info.Files {
+		file := &info.Files[i]
+		if stat, err := os.Stat(file.Path); err == nil {
+			file.Size = stat.Size()
+		}
+		if sum, err := fileChecksum(file.Path); err == nil {
+			file.SHA256 = sum
+		}
+	}
+	return info
+}
+
+// unixTime 将数据文件中的Unix时间戳转换为时间，0表示未记录
+func unixTime(sec int64) *time.Time {
+	if sec <= 0 {
+		return nil
+	}
+	t := time.Unix(sec, 0).UTC()
+	return &t
+}
@@ -0,0 +1,137 @@
+package ipquery
+
+import (
+	"bufio"
+	"fmt"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+)
+
+// RangeRecord IP段记录
+type RangeRecord struct {
+	Start  netip.Addr
+	End    netip.Addr
+	Region string
+}
+
+// RangeDatabase 基于有序IP段的内存数据库，同时支持IPv4和IPv6
+// 数据文件采用ip2region源数据格式，每行为: 起始IP|结束IP|国家|区域|省份|城市|ISP
+type RangeDatabase struct {
+	records []RangeRecord
+}
+
+// LoadRangeDatabase 从文本文件加载IP段数据库
+func LoadRangeDatabase(path string) (*RangeDatabase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open range database: %w", err)
+	}
+	defer f.Close()
+
+	// 相同的区域字符串只保留一份，降低内存占用
+	regions := make(map[string]string)
+	records := make([]RangeRecord, 0, 1024)
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "|", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid range at line %d: %q", lineNo, line)
+		}
+
+		start, err := netip.ParseAddr(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid start ip at line %d: %w", lineNo, err)
+		}
+		end, err := netip.ParseAddr(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid end ip at line %d: %w", lineNo, err)
+		}
+		start, end = start.Unmap(), end.Unmap()
+		if start.BitLen() != end.BitLen() || end.Less(start) {
+			return nil, fmt.Errorf("invalid range at line %d: %s-%s", lineNo, start, end)
+		}
+
+		region, ok := regions[parts[2]]
+		if !ok {
+			region = parts[2]
+			regions[region] = region
+		}
+
+		records = append(records, RangeRecord{Start: start, End: end, Region: region})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read range database: %w", err)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Start.Less(records[j].Start)
+	})
+
+	for i := 1; i < len(records); i++ {
+		if !records[i-1].End.Less(records[i].Start) {
+			return nil, fmt.Errorf("overlapping ranges: %s-%s and %s-%s",
+				records[i-1].Start, records[i-1].End, records[i].Start, records[i].End)
+		}
+	}
+
+	return &RangeDatabase{records: records}, nil
+}
+
+// Search 查找包含指定地址的IP段
+func (d *RangeDatabase) Search(addr netip.Addr) (*RangeRecord, bool) {
+	addr = addr.Unmap()
+
+	// 找到第一个起始地址大于addr的记录，前一条即为候选
+	i := sort.Search(len(d.records), func(i int) bool {
+		return addr.Less(d.records[i].Start)
+	})
+	if i == 0 {
+		return nil, false
+	}
+
+	record := &d.records[i-1]
+	if record.End.Less(addr) || record.Start.BitLen() != addr.BitLen() {
+		return nil, false
+	}
+
+	return record, true
+}
+
+// Len 返回IP段数量
+func (d *RangeDatabase) Len() int {
+	return len(d.records)
+}
+
+// Walk 按地址顺序遍历与[start, end]重叠的IP段，start和end必须属于同一地址族
+func (d *RangeDatabase) Walk(start, end netip.Addr, fn func(record *RangeRecord) error) error {
+	start, end = start.Unmap(), end.Unmap()
+
+	// 找到第一个结束地址不小于start的记录
+	i := sort.Search(len(d.records), func(i int) bool {
+		record := &d.records[i]
+		return record.Start.BitLen() > start.BitLen() ||
+			(record.Start.BitLen() == start.BitLen() && !record.End.Less(start))
+	})
+
+	for ; i < len(d.records); i++ {
+		record := &d.records[i]
+		if record.Start.BitLen() != start.BitLen() || end.Less(record.Start) {
+			return nil
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package ipquery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DatabaseOptions 创建查询提供者的选项，Type为注册的提供者名称，
+// 其余字段为各提供者使用的设置，提供者只读取与自己相关的部分
+type DatabaseOptions struct {
+	Type      string
+	Path      string
+	IPv6Path  string
+	LoadMode  LoadMode
+	PoolSize  int // 已废弃，见IP2RegionOptions.PoolSize
+	CacheSize int
+	MMDB      MMDBOptions
+	Composite CompositeOptions
+	Remote    RemoteOptions
+	// Validate 为true时NewValidatedProvider按Validation校验新创建的提供者
+	Validate   bool
+	Validation ValidationOptions
+}
+
+// CompositeOptions 组合提供者选项，成员通过注册表创建
+type CompositeOptions struct {
+	Members    []CompositeMemberOptions
+	Precedence map[string][]string
+}
+
+// CompositeMemberOptions 组合提供者成员选项
+type CompositeMemberOptions struct {
+	Type    string
+	Timeout time.Duration
+	Fields  []string
+}
+
+// ProviderFactory 根据选项创建查询提供者
+type ProviderFactory func(opts DatabaseOptions) (QueryProvider, error)
+
+// ProviderRegistration 提供者注册信息
+type ProviderRegistration struct {
+	// Factory 创建提供者
+	Factory ProviderFactory
+	// Paths 返回提供者依赖的数据文件，用于自动重载，可为nil
+	Paths func(opts DatabaseOptions) []string
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]ProviderRegistration)
+)
+
+// RegisterProvider 注册查询提供者，name对应配置中的ip_database.type
+// 重复注册同一名称会panic
+func RegisterProvider(name string, registration ProviderRegistration) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if registration.Factory == nil {
+		panic(fmt.Sprintf("ipquery: provider %q registered without factory", name))
+	}
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("ipquery: provider %q already registered", name))
+	}
+	registry[name] = registration
+}
+
+// ProviderNames 返回已注册的提供者名称
+func ProviderNames() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupProvider 查找提供者注册信息
+func lookupProvider(name string) (ProviderRegistration, error) {
+	registryMu.RLock()
+	registration, ok := registry[name]
+	registryMu.RUnlock()
+
+	if !ok {
+		return ProviderRegistration{}, fmt.Errorf("unknown ip database type %q (available: %s)",
+			name, strings.Join(ProviderNames(), ", "))
+	}
+	return registration, nil
+}
+
+// NewProvider 根据opts.Type创建查询提供者
+func NewProvider(opts DatabaseOptions) (QueryProvider, error) {
+	registration, err := lookupProvider(opts.Type)
+	if err != nil {
+		return nil, err
+	}
+	return registration.Factory(opts)
+}
+
+// DatabasePaths 返回opts.Type对应的提供者依赖的数据文件
+func DatabasePaths(opts DatabaseOptions) ([]string, error) {
+	registration, err := lookupProvider(opts.Type)
+	if err != nil {
+		return nil, err
+	}
+	if registration.Paths == nil {
+		return nil, nil
+	}
+
+	var paths []string
+	for _, path := range registration.Paths(opts) {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,106 @@
+package ipquery
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// providerRef 被引用计数的提供者，用于跟踪正在进行中的查询
+type providerRef struct {
+	provider QueryProvider
+	inflight sync.WaitGroup
+}
+
+// ReloadableProvider 支持热替换底层数据源的查询提供者
+// 替换时新查询立即使用新提供者，旧提供者在所有进行中的查询完成后才被关闭
+type ReloadableProvider struct {
+	mu      sync.RWMutex
+	current *providerRef
+	closed  bool
+	// generation 数据源版本，每次Swap后递增
+	generation atomic.Uint64
+}
+
+// NewReloadableProvider 创建新的可热替换提供者
+func NewReloadableProvider(provider QueryProvider) *ReloadableProvider {
+	return &ReloadableProvider{
+		current: &providerRef{provider: provider},
+	}
+}
+
+// acquire 获取当前提供者并登记一次进行中的查询
+func (p *ReloadableProvider) acquire() (*providerRef, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return nil, fmt.Errorf("provider not initialized")
+	}
+
+	ref := p.current
+	ref.inflight.Add(1)
+	return ref, nil
+}
+
+// Query 查询单个IP地址信息
+func (p *ReloadableProvider) Query(ip string) (*IPInfo, error) {
+	ref, err := p.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer ref.inflight.Done()
+
+	return ref.provider.Query(ip)
+}
+
+// BatchQuery 批量查询IP地址信息
+func (p *ReloadableProvider) BatchQuery(ips []string) ([]*IPInfo, error) {
+	ref, err := p.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer ref.inflight.Done()
+
+	return ref.provider.BatchQuery(ips)
+}
+
+// Swap 替换底层提供者，旧提供者在进行中的查询结束后异步关闭
+func (p *ReloadableProvider) Swap(provider QueryProvider) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return fmt.Errorf("provider already closed")
+	}
+	old := p.current
+	p.current = &providerRef{provider: provider}
+	p.generation.Add(1)
+	p.mu.Unlock()
+
+	go func() {
+		old.inflight.Wait()
+		old.provider.Close()
+	}()
+
+	return nil
+}
+
+// Generation 返回数据源版本，调用方可据此判断查询期间底层提供者是否被替换
+func (p *ReloadableProvider) Generation() uint64 {
+	return p.generation.Load()
+}
+
+// Close 关闭提供者，等待进行中的查询完成后释放资源
+func (p *ReloadableProvider) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	ref := p.current
+	p.mu.Unlock()
+
+	ref.inflight.Wait()
+	return ref.provider.Close()
+}
@@ -0,0 +1,423 @@
+package ipquery
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ushell/goip/pkg/logger"
+)
+
+func init() {
+	RegisterProvider("remote", ProviderRegistration{
+		Factory: newRemoteProviderFromOptions,
+		Paths: func(opts DatabaseOptions) []string {
+			paths, _ := DatabasePaths(remoteMemberOptions(opts))
+			return paths
+		},
+	})
+}
+
+// defaultRemoteTimeout 远程下载的默认超时
+const defaultRemoteTimeout = 5 * time.Minute
+
+// maxRemoteMetaSize 校验和与签名文件的大小上限
+const maxRemoteMetaSize = 64 << 10
+
+// RemoteOptions 远程数据库选项，type为remote时使用
+type RemoteOptions struct {
+	URL          string
+	Format       string        // 下载文件的格式：ip2region(默认)或mmdb
+	Timeout      time.Duration // 单次下载超时，<=0时使用默认值
+	SHA256       string
+	ChecksumURL  string
+	PublicKey    string // Base64编码的Ed25519公钥
+	SignatureURL string
+	Insecure     bool // 允许不校验下载内容
+}
+
+// remoteMemberOptions 返回下载文件实际使用的数据源选项
+// Remote.Format为mmdb时下载的文件作为MMDB.CityPath，否则作为ip2region的xdb
+func remoteMemberOptions(opts DatabaseOptions) DatabaseOptions {
+	member := opts
+	member.Type = opts.Remote.Format
+	if member.Type == "" {
+		member.Type = "ip2region"
+	}
+	if member.Type == "mmdb" {
+		member.MMDB.CityPath = opts.Path
+		member.MMDB.CountryPath = ""
+	}
+	return member
+}
+
+// newRemoteProviderFromOptions 打开已下载的数据库，本地文件不存在时先同步下载一次
+func newRemoteProviderFromOptions(opts DatabaseOptions) (QueryProvider, error) {
+	member := remoteMemberOptions(opts)
+	if member.Type == "remote" {
+		return nil, fmt.Errorf("invalid remote format: %s", opts.Remote.Format)
+	}
+
+	if _, err := os.Stat(opts.Path); errors.Is(err, os.ErrNotExist) {
+		fetcher, err := NewDatabaseFetcher(opts)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fetcher.Fetch(context.Background()); err != nil {
+			return nil, fmt.Errorf("initial download failed: %w", err)
+		}
+	}
+
+	return NewProvider(member)
+}
+
+// RemoteFetcherOptions 远程数据库下载配置
+type RemoteFetcherOptions struct {
+	// URL 数据库下载地址
+	URL string
+	// Path 本地数据库路径，下载完成并校验通过后原子替换
+	Path string
+	// SHA256 期望的SHA-256十六进制校验和，为空时不校验固定值
+	SHA256 string
+	// ChecksumURL 校验和文件地址，内容为"<sha256> [文件名]"，每次下载后获取
+	ChecksumURL string
+	// PublicKey Ed25519公钥，设置后要求数据库带有有效签名
+	PublicKey ed25519.PublicKey
+	// SignatureURL 分离签名地址，内容为原始64字节或其Base64编码，默认为URL加.sig后缀
+	SignatureURL string
+	// Client 下载使用的HTTP客户端，为nil时使用带超时的默认客户端
+	Client *http.Client
+	// Validate 替换前对下载的临时文件进行校验，为nil时不校验
+	Validate func(path string) error
+	// Insecure 允许SHA256、ChecksumURL和PublicKey都未设置，此时下载的任何内容都会被直接使用
+	Insecure bool
+}
+
+// RemoteFetcher 从HTTP地址下载IP数据库
+// 使用ETag和If-Modified-Since避免重复下载，校验通过后原子写入本地路径
+type RemoteFetcher struct {
+	opts RemoteFetcherOptions
+
+	mu sync.Mutex
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewRemoteFetcher 创建远程数据库下载器
+func NewRemoteFetcher(opts RemoteFetcherOptions) (*RemoteFetcher, error) {
+	if opts.URL == "" {
+		return nil, fmt.Errorf("remote url is required")
+	}
+	if opts.Path == "" {
+		return nil, fmt.Errorf("remote database path is required")
+	}
+	opts.SHA256 = strings.ToLower(strings.TrimSpace(opts.SHA256))
+	if !opts.verified() && !opts.Insecure {
+		return nil, fmt.Errorf("remote database requires sha256, checksum_url or public_key; set insecure to download without verification")
+	}
+	if opts.PublicKey != nil && len(opts.PublicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid ed25519 public key size: %d", len(opts.PublicKey))
+	}
+	if opts.PublicKey != nil && opts.SignatureURL == "" {
+		opts.SignatureURL = opts.URL + ".sig"
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: defaultRemoteTimeout}
+	}
+
+	return &RemoteFetcher{
+		opts: opts,
+		stop: make(chan struct{}),
+	}, nil
+}
+
+// NewDatabaseFetcher 根据数据库选项创建远程数据库下载器，下载到opts.Path
+func NewDatabaseFetcher(opts DatabaseOptions) (*RemoteFetcher, error) {
+	fetcherOpts := RemoteFetcherOptions{
+		URL:          opts.Remote.URL,
+		Path:         opts.Path,
+		SHA256:       opts.Remote.SHA256,
+		ChecksumURL:  opts.Remote.ChecksumURL,
+		SignatureURL: opts.Remote.SignatureURL,
+		Insecure:     opts.Remote.Insecure,
+	}
+	if opts.Remote.PublicKey != "" {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(opts.Remote.PublicKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote public key: %w", err)
+		}
+		fetcherOpts.PublicKey = ed25519.PublicKey(key)
+	}
+	if opts.Remote.Timeout > 0 {
+		fetcherOpts.Client = &http.Client{Timeout: opts.Remote.Timeout}
+	}
+	if opts.Validate {
+		// 下载的文件通过与启动时相同的校验后才替换本地文件
+		fetcherOpts.Validate = func(path string) error {
+			candidate := opts
+			candidate.Path = path
+			provider, err := NewValidatedProvider(remoteMemberOptions(candidate))
+			if err != nil {
+				return err
+			}
+			return provider.Close()
+		}
+	}
+	return NewRemoteFetcher(fetcherOpts)
+}
+
+// verified 判断是否配置了校验和或签名校验
+func (o RemoteFetcherOptions) verified() bool {
+	return o.SHA256 != "" || o.ChecksumURL != "" || o.PublicKey != nil
+}
+
+// Verified 判断下载的数据库是否经过校验和或签名校验
+func (f *RemoteFetcher) Verified() bool {
+	return f.opts.verified()
+}
+
+// etagPath 返回保存ETag的文件路径
+func (f *RemoteFetcher) etagPath() string {
+	return f.opts.Path + ".etag"
+}
+
+// Fetch 检查远程数据库是否更新，有更新时下载、校验并替换本地文件
+// 返回true表示本地文件已替换
+func (f *RemoteFetcher) Fetch(ctx context.Context) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.opts.URL, nil)
+	if err != nil {
+		return false, err
+	}
+	if stat, err := os.Stat(f.opts.Path); err == nil {
+		if etag, err := os.ReadFile(f.etagPath()); err == nil && len(bytes.TrimSpace(etag)) > 0 {
+			req.Header.Set("If-None-Match", string(bytes.TrimSpace(etag)))
+		}
+		req.Header.Set("If-Modified-Since", stat.ModTime().UTC().Format(http.TimeFormat))
+	}
+
+	resp, err := f.opts.Client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to download %s: %w", f.opts.URL, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return false, nil
+	case http.StatusOK:
+	default:
+		return false, fmt.Errorf("failed to download %s: unexpected status %s", f.opts.URL, resp.Status)
+	}
+
+	tmp, checksum, err := f.download(resp.Body)
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmp)
+
+	if err := f.verify(ctx, tmp, checksum); err != nil {
+		return false, err
+	}
+	if f.opts.Validate != nil {
+		if err := f.opts.Validate(tmp); err != nil {
+			return false, err
+		}
+	}
+
+	if lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		if err := os.Chtimes(tmp, lastModified, lastModified); err != nil {
+			return false, fmt.Errorf("failed to set modification time: %w", err)
+		}
+	}
+	if err := os.Rename(tmp, f.opts.Path); err != nil {
+		return false, fmt.Errorf("failed to replace %s: %w", f.opts.Path, err)
+	}
+
+	// ETag只用于下次请求，保存失败时退化为If-Modified-Since
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		os.WriteFile(f.etagPath(), []byte(etag+"\n"), 0644)
+	} else {
+		os.Remove(f.etagPath())
+	}
+
+	return true, nil
+}
+
+// download 将响应写入与目标文件同目录的临时文件，返回临时文件路径和SHA-256校验和
+func (f *RemoteFetcher) download(body io.Reader) (string, string, error) {
+	dir := filepath.Dir(f.opts.Path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(f.opts.Path)+".*.tmp")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), body)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", "", fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+
+	return tmp.Name(), hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// verify 校验下载文件的SHA-256和Ed25519签名
+func (f *RemoteFetcher) verify(ctx context.Context, path, checksum string) error {
+	if f.opts.SHA256 != "" && f.opts.SHA256 != checksum {
+		return fmt.Errorf("sha256 mismatch: expected %s, got %s", f.opts.SHA256, checksum)
+	}
+
+	if f.opts.ChecksumURL != "" {
+		data, err := f.fetchMeta(ctx, f.opts.ChecksumURL)
+		if err != nil {
+			return err
+		}
+		fields := strings.Fields(string(data))
+		if len(fields) == 0 {
+			return fmt.Errorf("empty checksum file: %s", f.opts.ChecksumURL)
+		}
+		if expected := strings.ToLower(fields[0]); expected != checksum {
+			return fmt.Errorf("sha256 mismatch: expected %s, got %s", expected, checksum)
+		}
+	}
+
+	if f.opts.PublicKey != nil {
+		sig, err := f.fetchMeta(ctx, f.opts.SignatureURL)
+		if err != nil {
+			return err
+		}
+		if len(sig) != ed25519.SignatureSize {
+			decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+			if err != nil {
+				return fmt.Errorf("invalid signature encoding: %w", err)
+			}
+			sig = decoded
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if !ed25519.Verify(f.opts.PublicKey, data, sig) {
+			return fmt.Errorf("invalid ed25519 signature")
+		}
+	}
+
+	return nil
+}
+
+// fetchMeta 下载校验和或签名文件
+func (f *RemoteFetcher) fetchMeta(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.opts.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: unexpected status %s", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteMetaSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", url, err)
+	}
+	return data, nil
+}
+
+// Start 启动后台下载，启动后立即检查一次，之后按interval定期检查
+// 本地文件被替换后执行onUpdate
+func (f *RemoteFetcher) Start(interval time.Duration, onUpdate func() error, logger *logger.Logger) {
+	f.wg.Add(1)
+	go f.run(interval, onUpdate, logger)
+}
+
+// Stop 停止后台下载，正在进行的下载会被取消
+func (f *RemoteFetcher) Stop() {
+	f.stopOnce.Do(func() {
+		close(f.stop)
+	})
+	f.wg.Wait()
+}
+
+// run 定时下载循环
+func (f *RemoteFetcher) run(interval time.Duration, onUpdate func() error, logger *logger.Logger) {
+	defer f.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-f.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	check := func() {
+		updated, err := f.Fetch(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.WithError(err).WithField("url", f.opts.URL).Error("下载远程数据库失败")
+			}
+			return
+		}
+		if !updated {
+			return
+		}
+		if err := onUpdate(); err != nil {
+			logger.WithError(err).WithField("path", f.opts.Path).Error("远程数据库更新后重新加载失败")
+			return
+		}
+		logger.WithField("url", f.opts.URL).Info("远程数据库已更新并重新加载")
+	}
+
+	check()
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package ipquery
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ushell/goip/pkg/logger"
+)
+
+// remoteStub 模拟远程数据库服务器，支持ETag和If-Modified-Since条件请求
+type remoteStub struct {
+	mu           sync.Mutex
+	body         []byte
+	etag         string
+	lastModified time.Time
+	signature    []byte
+	checksum     string
+	requests     []*http.Request
+}
+
+func (s *remoteStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Clone(context.Background()))
+
+	switch r.URL.Path {
+	case "/ip2region.xdb.sig":
+		w.Write(s.signature)
+		return
+	case "/ip2region.xdb.sha256":
+		w.Write([]byte(s.checksum + "  ip2region.xdb\n"))
+		return
+	case "/ip2region.xdb":
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" && inm == s.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if ims, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !s.lastModified.After(ims) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if s.etag != "" {
+		w.Header().Set("ETag", s.etag)
+	}
+	w.Header().Set("Last-Modified", s.lastModified.UTC().Format(http.TimeFormat))
+	w.Write(s.body)
+}
+
+// lastRequest 返回对路径path的最后一次请求
+func (s *remoteStub) lastRequest(path string) *http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.requests) - 1; i >= 0; i-- {
+		if s.requests[i].URL.Path == path {
+			return s.requests[i]
+		}
+	}
+	return nil
+}
+
+func newRemoteStub(t *testing.T, body string) (*remoteStub, *httptest.Server) {
+	t.Helper()
+	stub := &remoteStub{
+		body:         []byte(body),
+		etag:         `"v1"`,
+		lastModified: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+	return stub, server
+}
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// assertFile 检查文件内容，并确认目录中没有残留的临时文件
+func assertFile(t *testing.T, path, want string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if string(data) != want {
+		t.Fatalf("%s = %q, want %q", path, data, want)
+	}
+
+	tmps, err := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tmps) > 0 {
+		t.Fatalf("temporary files left behind: %v", tmps)
+	}
+}
+
+func TestRemoteFetcherReplacesFile(t *testing.T) {
+	stub, server := newRemoteStub(t, "new database")
+	path := filepath.Join(t.TempDir(), "ip2region.xdb")
+	if err := os.WriteFile(path, []byte("old database"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	fetcher, err := NewRemoteFetcher(RemoteFetcherOptions{
+		URL:    server.URL + "/ip2region.xdb",
+		Path:   path,
+		SHA256: strings.ToUpper(sha256Hex("new database")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := fetcher.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if !updated {
+		t.Fatal("Fetch reported no update for a 200 response")
+	}
+	assertFile(t, path, "new database")
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stat.ModTime().Equal(stub.lastModified) {
+		t.Errorf("modification time = %v, want Last-Modified %v", stat.ModTime(), stub.lastModified)
+	}
+	etag, err := os.ReadFile(path + ".etag")
+	if err != nil {
+		t.Fatalf("read etag: %v", err)
+	}
+	if strings.TrimSpace(string(etag)) != stub.etag {
+		t.Errorf("saved etag = %q, want %q", etag, stub.etag)
+	}
+}
+
+func TestRemoteFetcherRequiresVerification(t *testing.T) {
+	_, server := newRemoteStub(t, "database")
+	path := filepath.Join(t.TempDir(), "ip2region.xdb")
+
+	_, err := NewRemoteFetcher(RemoteFetcherOptions{
+		URL:    server.URL + "/ip2region.xdb",
+		Path:   path,
+		SHA256: "  ",
+	})
+	if err == nil || !strings.Contains(err.Error(), "insecure") {
+		t.Fatalf("NewRemoteFetcher without verification = %v, want an error mentioning insecure", err)
+	}
+
+	// 通过数据库选项创建时同样要求校验
+	_, err = NewDatabaseFetcher(DatabaseOptions{Type: "remote", Path: path, Remote: RemoteOptions{URL: server.URL + "/ip2region.xdb"}})
+	if err == nil {
+		t.Fatal("NewDatabaseFetcher accepted a remote database without verification")
+	}
+
+	fetcher, err := NewRemoteFetcher(RemoteFetcherOptions{
+		URL:      server.URL + "/ip2region.xdb",
+		Path:     path,
+		Insecure: true,
+	})
+	if err != nil {
+		t.Fatalf("NewRemoteFetcher with insecure: %v", err)
+	}
+	if fetcher.Verified() {
+		t.Error("Verified() = true without checksum or signature")
+	}
+}
+
+func TestRemoteFetcherConditionalRequest(t *testing.T) {
+	stub, server := newRemoteStub(t, "database")
+	path := filepath.Join(t.TempDir(), "ip2region.xdb")
+
+	fetcher, err := NewRemoteFetcher(RemoteFetcherOptions{
+		URL:      server.URL + "/ip2region.xdb",
+		Path:     path,
+		Insecure: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updated, err := fetcher.Fetch(context.Background()); err != nil || !updated {
+		t.Fatalf("first Fetch = %v, %v; want true, nil", updated, err)
+	}
+	if req := stub.lastRequest("/ip2region.xdb"); req.Header.Get("If-None-Match") != "" {
+		t.Errorf("first request sent If-None-Match %q without a local file", req.Header.Get("If-None-Match"))
+	}
+
+	updated, err := fetcher.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("second Fetch: %v", err)
+	}
+	if updated {
+		t.Fatal("second Fetch reported an update for a 304 response")
+	}
+
+	req := stub.lastRequest("/ip2region.xdb")
+	if got := req.Header.Get("If-None-Match"); got != stub.etag {
+		t.Errorf("If-None-Match = %q, want %q", got, stub.etag)
+	}
+	ims, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		t.Fatalf("If-Modified-Since %q: %v", req.Header.Get("If-Modified-Since"), err)
+	}
+	if !ims.Equal(stub.lastModified) {
+		t.Errorf("If-Modified-Since = %v, want %v", ims, stub.lastModified)
+	}
+	assertFile(t, path, "database")
+
+	// 没有ETag时仅凭If-Modified-Since判断
+	if err := os.Remove(path + ".etag"); err != nil {
+		t.Fatal(err)
+	}
+	if updated, err := fetcher.Fetch(context.Background()); err != nil || updated {
+		t.Fatalf("Fetch without etag = %v, %v; want false, nil", updated, err)
+	}
+}
+
+func TestRemoteFetcherRejectsChecksumMismatch(t *testing.T) {
+	stub, server := newRemoteStub(t, "tampered database")
+	stub.checksum = sha256Hex("expected database")
+
+	tests := []struct {
+		name string
+		opts RemoteFetcherOptions
+	}{
+		{"sha256", RemoteFetcherOptions{SHA256: sha256Hex("expected database")}},
+		{"checksum_url", RemoteFetcherOptions{ChecksumURL: server.URL + "/ip2region.xdb.sha256"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "ip2region.xdb")
+			if err := os.WriteFile(path, []byte("current database"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			opts := tt.opts
+			opts.URL = server.URL + "/ip2region.xdb"
+			opts.Path = path
+			fetcher, err := NewRemoteFetcher(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// 本地文件的修改时间早于Last-Modified，服务器返回完整内容
+			old := stub.lastModified.Add(-time.Hour)
+			if err := os.Chtimes(path, old, old); err != nil {
+				t.Fatal(err)
+			}
+
+			updated, err := fetcher.Fetch(context.Background())
+			if err == nil || !strings.Contains(err.Error(), "sha256 mismatch") {
+				t.Fatalf("Fetch error = %v, want sha256 mismatch", err)
+			}
+			if updated {
+				t.Error("Fetch reported an update after a checksum mismatch")
+			}
+			assertFile(t, path, "current database")
+		})
+	}
+}
+
+func TestRemoteFetcherSignature(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		signature []byte
+		wantErr   string
+	}{
+		{"valid raw", ed25519.Sign(private, []byte("signed database")), ""},
+		{"valid base64", []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(private, []byte("signed database")))), ""},
+		{"other content", ed25519.Sign(private, []byte("other database")), "invalid ed25519 signature"},
+		{"malformed", []byte("not a signature"), "invalid signature encoding"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub, server := newRemoteStub(t, "signed database")
+			stub.signature = tt.signature
+
+			path := filepath.Join(t.TempDir(), "ip2region.xdb")
+			if err := os.WriteFile(path, []byte("current database"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			old := stub.lastModified.Add(-time.Hour)
+			if err := os.Chtimes(path, old, old); err != nil {
+				t.Fatal(err)
+			}
+
+			fetcher, err := NewRemoteFetcher(RemoteFetcherOptions{
+				URL:       server.URL + "/ip2region.xdb",
+				Path:      path,
+				PublicKey: public,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			updated, err := fetcher.Fetch(context.Background())
+			if tt.wantErr == "" {
+				if err != nil || !updated {
+					t.Fatalf("Fetch = %v, %v; want true, nil", updated, err)
+				}
+				assertFile(t, path, "signed database")
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Fetch error = %v, want %q", err, tt.wantErr)
+			}
+			if updated {
+				t.Error("Fetch reported an update after a signature failure")
+			}
+			assertFile(t, path, "current database")
+		})
+	}
+}
+
+func TestRemoteFetcherStartCallsOnUpdateAfterReplace(t *testing.T) {
+	stub, server := newRemoteStub(t, "new database")
+	path := filepath.Join(t.TempDir(), "ip2region.xdb")
+	if err := os.WriteFile(path, []byte("old database"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := stub.lastModified.Add(-time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	fetcher, err := NewRemoteFetcher(RemoteFetcherOptions{
+		URL:      server.URL + "/ip2region.xdb",
+		Path:     path,
+		Insecure: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(chan string, 10)
+	fetcher.Start(10*time.Millisecond, func() error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		seen <- string(data)
+		return nil
+	}, logger.New("error", "text", "stderr"))
+	defer fetcher.Stop()
+
+	select {
+	case content := <-seen:
+		if content != "new database" {
+			t.Fatalf("onUpdate saw %q, want the replaced file", content)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("onUpdate was not called")
+	}
+
+	// 之后的检查得到304，不再触发onUpdate
+	time.Sleep(50 * time.Millisecond)
+	fetcher.Stop()
+	select {
+	case content := <-seen:
+		t.Fatalf("onUpdate called again without an update (saw %q)", content)
+	default:
+	}
+}
@@ -0,0 +1,306 @@
+package ipquery
+
+import (
+	"bufio"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ThreatCategory 信誉列表类别
+type ThreatCategory string
+
+// 信誉列表类别定义，按usage_type的优先级从高到低排列
+const (
+	ThreatCategoryTor     ThreatCategory = "tor"     // Tor出口节点
+	ThreatCategoryProxy   ThreatCategory = "proxy"   // 公共代理
+	ThreatCategoryVPN     ThreatCategory = "vpn"     // VPN服务
+	ThreatCategoryHosting ThreatCategory = "hosting" // 数据中心/托管
+)
+
+// threatCategories 按优先级排列的类别
+var threatCategories = []ThreatCategory{
+	ThreatCategoryTor,
+	ThreatCategoryProxy,
+	ThreatCategoryVPN,
+	ThreatCategoryHosting,
+}
+
+// ThreatInfo IP信誉信息
+type ThreatInfo struct {
+	IsHosting bool     `json:"is_hosting"`
+	IsVPN     bool     `json:"is_vpn"`
+	IsProxy   bool     `json:"is_proxy"`
+	IsTor     bool     `json:"is_tor"`
+	UsageType string   `json:"usage_type,omitempty"` // 命中的最高优先级类别: tor, proxy, vpn, hosting
+	Sources   []string `json:"sources,omitempty"`    // 命中的列表名称
+}
+
+// set 标记类别
+func (t *ThreatInfo) set(category ThreatCategory) {
+	switch category {
+	case ThreatCategoryTor:
+		t.IsTor = true
+	case ThreatCategoryProxy:
+		t.IsProxy = true
+	case ThreatCategoryVPN:
+		t.IsVPN = true
+	case ThreatCategoryHosting:
+		t.IsHosting = true
+	}
+}
+
+// has 判断是否命中类别
+func (t *ThreatInfo) has(category ThreatCategory) bool {
+	switch category {
+	case ThreatCategoryTor:
+		return t.IsTor
+	case ThreatCategoryProxy:
+		return t.IsProxy
+	case ThreatCategoryVPN:
+		return t.IsVPN
+	case ThreatCategoryHosting:
+		return t.IsHosting
+	default:
+		return false
+	}
+}
+
+// ParseThreatCategory 解析信誉列表类别
+func ParseThreatCategory(s string) (ThreatCategory, error) {
+	category := ThreatCategory(strings.ToLower(strings.TrimSpace(s)))
+	for _, c := range threatCategories {
+		if c == category {
+			return category, nil
+		}
+	}
+	return "", fmt.Errorf("unknown reputation category: %s", s)
+}
+
+// addrRange 闭区间IP段
+type addrRange struct {
+	start netip.Addr
+	end   netip.Addr
+}
+
+// AddrSet 由有序且不重叠的IP段组成的地址集合
+type AddrSet struct {
+	ranges []addrRange
+}
+
+// LoadAddrSet 从文本文件加载地址集合
+// 每行为一个IP地址、CIDR或"起始IP-结束IP"形式的IP段，#之后为注释
+func LoadAddrSet(path string) (*AddrSet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open address list: %w", err)
+	}
+	defer f.Close()
+
+	var ranges []addrRange
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		r, err := parseAddrRange(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address at line %d: %w", lineNo, err)
+		}
+		ranges = append(ranges, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read address list: %w", err)
+	}
+
+	return newAddrSet(ranges), nil
+}
+
+// parseAddrRange 解析IP地址、CIDR或IP段
+func parseAddrRange(s string) (addrRange, error) {
+	if start, end, ok := strings.Cut(s, "-"); ok {
+		startAddr, err := netip.ParseAddr(strings.TrimSpace(start))
+		if err != nil {
+			return addrRange{}, err
+		}
+		endAddr, err := netip.ParseAddr(strings.TrimSpace(end))
+		if err != nil {
+			return addrRange{}, err
+		}
+		startAddr, endAddr = startAddr.Unmap(), endAddr.Unmap()
+		if startAddr.BitLen() != endAddr.BitLen() || endAddr.Less(startAddr) {
+			return addrRange{}, fmt.Errorf("invalid range %s", s)
+		}
+		return addrRange{start: startAddr, end: endAddr}, nil
+	}
+
+	prefix, err := parseCIDROrAddr(s)
+	if err != nil {
+		return addrRange{}, err
+	}
+	return addrRange{start: prefix.Addr(), end: LastAddr(prefix)}, nil
+}
+
+// newAddrSet 排序并合并重叠或相邻的IP段
+func newAddrSet(ranges []addrRange) *AddrSet {
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].start.Less(ranges[j].start)
+	})
+
+	merged := make([]addrRange, 0, len(ranges))
+	for _, r := range ranges {
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+			if last.end.BitLen() == r.start.BitLen() &&
+				(!last.end.Less(r.start) || last.end.Next() == r.start) {
+				if last.end.Less(r.end) {
+					last.end = r.end
+				}
+				continue
+			}
+		}
+		merged = append(merged, r)
+	}
+
+	return &AddrSet{ranges: merged}
+}
+
+// Contains 判断地址是否在集合中
+func (s *AddrSet) Contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	i := sort.Search(len(s.ranges), func(i int) bool {
+		return addr.Less(s.ranges[i].start)
+	})
+	if i == 0 {
+		return false
+	}
+
+	r := s.ranges[i-1]
+	return r.start.BitLen() == addr.BitLen() && !r.end.Less(addr)
+}
+
+// Len 返回合并后的IP段数量
+func (s *AddrSet) Len() int {
+	return len(s.ranges)
+}
+
+// ReputationList 信誉列表配置
+type ReputationList struct {
+	Name     string // 列表名称，为空时使用文件名
+	Category ThreatCategory
+	Path     string
+}
+
+// reputationSet 已加载的信誉列表
+type reputationSet struct {
+	list ReputationList
+	set  *AddrSet
+}
+
+// ReputationEnricher 根据本地IP列表标记数据中心、VPN、公共代理和Tor出口节点
+type ReputationEnricher struct {
+	lists []ReputationList
+
+	mu   sync.RWMutex
+	sets []reputationSet
+}
+
+// NewReputationEnricher 创建信誉增强器
+func NewReputationEnricher(lists []ReputationList) (*ReputationEnricher, error) {
+	for i := range lists {
+		category, err := ParseThreatCategory(string(lists[i].Category))
+		if err != nil {
+			return nil, err
+		}
+		lists[i].Category = category
+		if lists[i].Path == "" {
+			return nil, fmt.Errorf("reputation list #%d: path is required", i+1)
+		}
+		if lists[i].Name == "" {
+			lists[i].Name = strings.TrimSuffix(filepath.Base(lists[i].Path), filepath.Ext(lists[i].Path))
+		}
+	}
+
+	e := &ReputationEnricher{lists: lists}
+	if err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Name 返回增强器名称
+func (e *ReputationEnricher) Name() string {
+	return "reputation"
+}
+
+// Paths 返回所有列表文件
+func (e *ReputationEnricher) Paths() []string {
+	paths := make([]string, 0, len(e.lists))
+	for _, list := range e.lists {
+		paths = append(paths, list.Path)
+	}
+	return paths
+}
+
+// Enrich 设置IP信誉信息
+func (e *ReputationEnricher) Enrich(info *IPInfo) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(info.IP))
+	if err != nil {
+		return
+	}
+
+	threat := &ThreatInfo{}
+
+	e.mu.RLock()
+	for _, s := range e.sets {
+		if s.set.Contains(addr) {
+			threat.set(s.list.Category)
+			threat.Sources = append(threat.Sources, s.list.Name)
+		}
+	}
+	e.mu.RUnlock()
+
+	for _, category := range threatCategories {
+		if threat.has(category) {
+			threat.UsageType = string(category)
+			break
+		}
+	}
+
+	info.Threat = threat
+}
+
+// Reload 重新加载所有列表，任一列表加载失败时继续使用原数据
+func (e *ReputationEnricher) Reload() error {
+	sets := make([]reputationSet, 0, len(e.lists))
+	for _, list := range e.lists {
+		set, err := LoadAddrSet(list.Path)
+		if err != nil {
+			return fmt.Errorf("reputation list %s: %w", list.Name, err)
+		}
+		sets = append(sets, reputationSet{list: list, set: set})
+	}
+
+	e.mu.Lock()
+	e.sets = sets
+	e.mu.Unlock()
+	return nil
+}
+
+// Close 释放资源
+func (e *ReputationEnricher) Close() error {
+	return nil
+}
@@ -0,0 +1,242 @@
+package ipquery
+
+import (
+	"errors"
+	"net/netip"
+	"strings"
+
+	"github.com/ushell/goip/pkg/i18n"
+)
+
+// 完整地址空间
+var (
+	ipv4First = netip.AddrFrom4([4]byte{})
+	ipv4Last  = netip.AddrFrom4([4]byte{255, 255, 255, 255})
+	ipv6First = netip.IPv6Unspecified()
+	ipv6Last  = netip.AddrFrom16([16]byte{
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	})
+)
+
+// RangeFilter IP段反查条件，为空的字段不参与匹配，所有非空字段都匹配时IP段才匹配
+// 各字段可使用数据源中的中文名称或英文名称，国家还可以使用ISO 3166-1代码
+type RangeFilter struct {
+	Country string `json:"country,omitempty"`
+	Region  string `json:"region,omitempty"`
+	City    string `json:"city,omitempty"`
+	ISP     string `json:"isp,omitempty"`
+}
+
+// IsEmpty 判断是否未设置任何条件
+func (f RangeFilter) IsEmpty() bool {
+	return strings.TrimSpace(f.Country) == "" && strings.TrimSpace(f.Region) == "" &&
+		strings.TrimSpace(f.City) == "" && strings.TrimSpace(f.ISP) == ""
+}
+
+// Match 判断IP信息是否满足条件
+// 省份和城市忽略"省"、"市"等行政区划后缀，ISP忽略"中国"前缀，如"中国移动"可以匹配"移动"
+func (f RangeFilter) Match(info *IPInfo) bool {
+	if country := strings.TrimSpace(f.Country); country != "" {
+		if !strings.EqualFold(country, info.CountryCode) &&
+			!placeMatches(country, info.Country, i18n.Country(info.Country, info.CountryCode, i18n.LangEn)) {
+			return false
+		}
+	}
+	if f.Region != "" && !placeMatches(f.Region, info.Region, i18n.Region(info.Region, i18n.LangEn)) {
+		return false
+	}
+	if f.City != "" && !placeMatches(f.City, info.City, i18n.City(info.City, i18n.LangEn)) {
+		return false
+	}
+	if f.ISP != "" && !ispMatches(f.ISP, info.ISP) {
+		return false
+	}
+	return true
+}
+
+// placeMatches 判断条件是否与地名的中文或英文名称相同
+func placeMatches(want string, names ...string) bool {
+	want = normalizePlaceName(want)
+	if want == "" {
+		return true
+	}
+	for _, name := range names {
+		if name != "" && normalizePlaceName(name) == want {
+			return true
+		}
+	}
+	return false
+}
+
+// ispMatches 判断条件是否与ISP的中文或英文名称相同
+func ispMatches(want, isp string) bool {
+	want = normalizeISPName(want)
+	if want == "" {
+		return true
+	}
+	if isp == "" {
+		return false
+	}
+	return normalizeISPName(isp) == want || normalizeISPName(i18n.ISP(isp, i18n.LangEn)) == want
+}
+
+// normalizeISPName 去除ISP名称的"中国"前缀
+func normalizeISPName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if trimmed := strings.TrimPrefix(name, "中国"); trimmed != "" {
+		return trimmed
+	}
+	return name
+}
+
+// FindRanges 按地址顺序遍历IPv4和IPv6的全部IP段，对满足条件的IP段调用fn
+// 回调中info.IP为IP段起始地址，info.Range为该IP段
+func FindRanges(provider QueryProvider, filter RangeFilter, fn RangeFunc) error {
+	return findRangesAfter(provider, filter, netip.Addr{}, fn)
+}
+
+// findRangesAfter 从after之后的地址开始遍历满足条件的IP段，after无效时遍历全部地址空间
+// IPv4地址之后先遍历剩余的IPv4段再遍历IPv6段；结束地址不大于after的IP段被跳过
+func findRangesAfter(provider QueryProvider, filter RangeFilter, after netip.Addr, fn RangeFunc) error {
+	match := func(start, end netip.Addr, info *IPInfo) error {
+		if after.IsValid() && !after.Less(end) {
+			return nil
+		}
+		if !filter.Match(info) {
+			return nil
+		}
+		info.IP = start.String()
+		info.Range = NewIPRange(start, end)
+		return fn(start, end, info)
+	}
+
+	v4Start, v6Start := ipv4First, ipv6First
+	if after.IsValid() {
+		after = after.Unmap()
+		if after.Is4() {
+			v4Start = after.Next()
+		} else {
+			v4Start, v6Start = netip.Addr{}, after.Next()
+		}
+	}
+
+	// 游标为地址空间的最后一个地址时Next返回无效地址，表示该地址族已遍历完
+	if v4Start.IsValid() {
+		if err := WalkRanges(provider, v4Start, ipv4Last, match); err != nil {
+			return err
+		}
+	}
+	if v6Start.IsValid() {
+		return WalkRanges(provider, v6Start, ipv6Last, match)
+	}
+	return nil
+}
+
+// RangePageQuery 分页反查参数
+type RangePageQuery struct {
+	After  netip.Addr // 游标，从该地址之后继续遍历，通常为上一页的Next
+	Offset int        // 跳过游标之后的前Offset条匹配记录
+	Limit  int
+	Total  bool // 是否统计全部匹配数量，需要遍历游标之后的整个数据库
+}
+
+// RangePage 分页的IP段反查结果
+type RangePage struct {
+	Filter RangeFilter `json:"filter"`
+	After  string      `json:"after,omitempty"` // 本页使用的游标
+	Total  *int        `json:"total,omitempty"` // 游标之后满足条件的IP段总数，仅在请求时统计
+	Offset int         `json:"offset"`          // 本页第一条记录在游标之后的序号
+	Limit  int         `json:"limit"`
+	Ranges []*IPInfo   `json:"ranges"`
+	Next   string      `json:"next,omitempty"` // 还有更多结果时为本页最后一个IP段的结束地址，作为下一页的after
+}
+
+// errRangePageFull 本页已满且确认还有更多结果，提前结束遍历
+var errRangePageFull = errors.New("range page full")
+
+// FindRangePage 返回游标之后满足条件的第offset条起最多limit条IP段
+// 找到本页之后的第一条匹配记录即停止遍历，只有请求统计总数时才遍历到数据库末尾
+func FindRangePage(provider QueryProvider, filter RangeFilter, query RangePageQuery) (*RangePage, error) {
+	page := &RangePage{
+		Filter: filter,
+		Offset: query.Offset,
+		Limit:  query.Limit,
+		Ranges: []*IPInfo{},
+	}
+	if query.After.IsValid() {
+		page.After = query.After.String()
+	}
+
+	matched := 0
+	var last netip.Addr
+	more := false
+	err := findRangesAfter(provider, filter, query.After, func(start, end netip.Addr, info *IPInfo) error {
+		matched++
+		switch {
+		case matched <= query.Offset:
+		case len(page.Ranges) < query.Limit:
+			page.Ranges = append(page.Ranges, info)
+			last = end
+		default:
+			more = true
+			if !query.Total {
+				return errRangePageFull
+			}
+		}
+		return nil
+	})
+	if err != nil && err != errRangePageFull {
+		return nil, err
+	}
+
+	if more && last.IsValid() {
+		page.Next = last.String()
+	}
+	if query.Total {
+		page.Total = &matched
+	}
+	return page, nil
+}
+
+// RangeMerger 合并按地址顺序输入的相邻IP段，并以最少的CIDR输出
+type RangeMerger struct {
+	emit       func(netip.Prefix) error
+	start, end netip.Addr
+	pending    bool
+}
+
+// NewRangeMerger 创建IP段合并器，emit按地址顺序接收合并后的CIDR
+func NewRangeMerger(emit func(netip.Prefix) error) *RangeMerger {
+	return &RangeMerger{emit: emit}
+}
+
+// Add 添加一个IP段，与上一个IP段相邻时合并，否则输出上一个IP段
+func (m *RangeMerger) Add(start, end netip.Addr) error {
+	start, end = start.Unmap(), end.Unmap()
+	if m.pending && m.end.BitLen() == start.BitLen() && m.end.Next() == start {
+		m.end = end
+		return nil
+	}
+
+	if err := m.Flush(); err != nil {
+		return err
+	}
+	m.start, m.end, m.pending = start, end, true
+	return nil
+}
+
+// Flush 输出尚未输出的IP段
+func (m *RangeMerger) Flush() error {
+	if !m.pending {
+		return nil
+	}
+	m.pending = false
+
+	for _, prefix := range RangeToCIDRs(m.start, m.end) {
+		if err := m.emit(prefix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
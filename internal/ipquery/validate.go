@@ -0,0 +1,172 @@
+package ipquery
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Verifier 支持结构校验的查询提供者
+type Verifier interface {
+	// Verify 完整检查数据文件结构，发现截断或损坏时返回错误
+	Verify() error
+}
+
+// Canary 校验用的已知IP及其期望结果，为空的字段不检查
+type Canary struct {
+	IP          string
+	Country     string
+	CountryCode string
+	Region      string
+	City        string
+	ISP         string
+}
+
+// defaultValidationSamples 计算字段填充率时默认的采样地址数
+const defaultValidationSamples = 1000
+
+// completenessFields 可检查填充率的字段
+var completenessFields = map[string]func(info *IPInfo) string{
+	"country":      func(info *IPInfo) string { return info.Country },
+	"country_code": func(info *IPInfo) string { return info.CountryCode },
+	"region":       func(info *IPInfo) string { return info.Region },
+	"city":         func(info *IPInfo) string { return info.City },
+	"isp":          func(info *IPInfo) string { return info.ISP },
+}
+
+// ValidationOptions 数据库校验选项
+type ValidationOptions struct {
+	// Canaries 必须返回期望结果的IP
+	Canaries []Canary
+	// Completeness 字段 -> 最低填充率(0~1)，在IPv4公网地址空间中均匀采样计算
+	Completeness map[string]float64
+	// Samples 计算填充率的采样地址数，<=0时使用默认值
+	Samples int
+}
+
+// ValidateProvider 在提供者投入使用前进行校验：
+// 数据文件结构检查（提供者实现Verifier时）、哨兵IP结果检查、字段填充率检查
+func ValidateProvider(provider QueryProvider, opts ValidationOptions) error {
+	if verifier, ok := provider.(Verifier); ok {
+		if err := verifier.Verify(); err != nil {
+			return fmt.Errorf("structure check failed: %w", err)
+		}
+	}
+
+	var errs []error
+	for _, canary := range opts.Canaries {
+		if err := checkCanary(provider, canary); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("canary check failed: %w", err)
+	}
+
+	if len(opts.Completeness) > 0 {
+		if err := checkCompleteness(provider, opts.Completeness, opts.Samples); err != nil {
+			return fmt.Errorf("completeness check failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkCanary 检查哨兵IP的查询结果
+func checkCanary(provider QueryProvider, canary Canary) error {
+	info, err := provider.Query(canary.IP)
+	if err != nil {
+		return fmt.Errorf("%s: %w", canary.IP, err)
+	}
+	if !info.IsValid {
+		return fmt.Errorf("%s: %s", canary.IP, info.ErrorMessage)
+	}
+
+	checks := []struct {
+		field    string
+		expected string
+		actual   string
+	}{
+		{"country", canary.Country, info.Country},
+		{"country_code", canary.CountryCode, info.CountryCode},
+		{"region", canary.Region, info.Region},
+		{"city", canary.City, info.City},
+		{"isp", canary.ISP, info.ISP},
+	}
+	for _, c := range checks {
+		if c.expected != "" && !strings.EqualFold(strings.TrimSpace(c.expected), strings.TrimSpace(c.actual)) {
+			return fmt.Errorf("%s: %s expected %q, got %q", canary.IP, c.field, c.expected, c.actual)
+		}
+	}
+	return nil
+}
+
+// checkCompleteness 在IPv4公网地址空间中均匀采样，检查各字段的填充率
+func checkCompleteness(provider QueryProvider, thresholds map[string]float64, samples int) error {
+	for field := range thresholds {
+		if _, ok := completenessFields[field]; !ok {
+			return fmt.Errorf("unknown field: %s", field)
+		}
+	}
+	if samples <= 0 {
+		samples = defaultValidationSamples
+	}
+
+	filled := make(map[string]int, len(thresholds))
+	total := 0
+	step := uint64(1<<32) / uint64(samples)
+	for i := 0; i < samples; i++ {
+		// 在每个采样区间的中点取样，避开网段边界上的网络地址
+		addr := uint32ToAddr(uint32(uint64(i)*step + step/2))
+		if Classify(addr).Type != AddressTypeGlobal {
+			continue
+		}
+
+		total++
+		info, err := provider.Query(addr.String())
+		if err != nil || !info.IsValid {
+			continue
+		}
+		for field := range thresholds {
+			if completenessFields[field](info) != "" {
+				filled[field]++
+			}
+		}
+	}
+	if total == 0 {
+		return fmt.Errorf("no public address sampled")
+	}
+
+	fields := make([]string, 0, len(thresholds))
+	for field := range thresholds {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var errs []error
+	for _, field := range fields {
+		threshold := thresholds[field]
+		if ratio := float64(filled[field]) / float64(total); ratio < threshold {
+			errs = append(errs, fmt.Errorf("%s filled for %.1f%% of %d sampled addresses, expected at least %.1f%%",
+				field, ratio*100, total, threshold*100))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NewValidatedProvider 创建提供者并在启用校验时进行校验，校验失败时关闭提供者并返回错误
+func NewValidatedProvider(opts DatabaseOptions) (QueryProvider, error) {
+	provider, err := NewProvider(opts)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.Validate {
+		return provider, nil
+	}
+
+	if err := ValidateProvider(provider, opts.Validation); err != nil {
+		provider.Close()
+		return nil, fmt.Errorf("database validation failed: %w", err)
+	}
+	return provider, nil
+}
@@ -0,0 +1,170 @@
+package ipquery
+
+import (
+	"errors"
+	"math/big"
+	"net/netip"
+	"sort"
+)
+
+// ErrRangeWalkNotSupported 数据源不支持遍历IP段
+var ErrRangeWalkNotSupported = errors.New("range walk not supported by provider")
+
+// RangeFunc IP段遍历回调，start和end为数据源中的完整范围，info.Range为空，由调用方按需设置
+type RangeFunc func(start, end netip.Addr, info *IPInfo) error
+
+// RangeWalker 支持按地址顺序遍历IP段的查询提供者
+type RangeWalker interface {
+	WalkRanges(start, end netip.Addr, fn RangeFunc) error
+}
+
+// WalkRanges 遍历提供者中与[start, end]重叠的IP段，提供者不支持时返回ErrRangeWalkNotSupported
+func WalkRanges(provider QueryProvider, start, end netip.Addr, fn RangeFunc) error {
+	walker, ok := provider.(RangeWalker)
+	if !ok {
+		return ErrRangeWalkNotSupported
+	}
+	return walker.WalkRanges(start, end, fn)
+}
+
+// WalkRanges 在当前提供者上遍历IP段，遍历期间旧提供者不会被关闭
+func (p *ReloadableProvider) WalkRanges(start, end netip.Addr, fn RangeFunc) error {
+	ref, err := p.acquire()
+	if err != nil {
+		return err
+	}
+	defer ref.inflight.Done()
+
+	return WalkRanges(ref.provider, start, end, fn)
+}
+
+// WalkRanges 遍历底层数据源的IP段，覆盖表只作用于单个地址的查询
+func (p *OverrideProvider) WalkRanges(start, end netip.Addr, fn RangeFunc) error {
+	return WalkRanges(p.provider, start, end, fn)
+}
+
+// CIDRSummaryItem 网段内某个国家或ISP的汇总
+type CIDRSummaryItem struct {
+	Name   string  `json:"name"`
+	Code   string  `json:"code,omitempty"` // 国家代码，仅国家汇总
+	Ranges int     `json:"ranges"`         // IP段数量
+	Share  float64 `json:"share"`          // 占网段地址空间的比例
+}
+
+// CIDRReport 网段查询结果
+type CIDRReport struct {
+	Prefix      string            `json:"prefix"`
+	Ranges      []*IPInfo         `json:"ranges"`       // 按地址顺序排列的子范围，已裁剪到网段内
+	TotalRanges int               `json:"total_ranges"` // 子范围总数
+	Truncated   bool              `json:"truncated"`    // 子范围超过上限时只返回前面的部分
+	Countries   []CIDRSummaryItem `json:"countries"`
+	ISPs        []CIDRSummaryItem `json:"isps"`
+}
+
+// unknownName 汇总中名称为空时使用的名称
+const unknownName = "未知"
+
+// SummarizePrefix 遍历与网段重叠的IP段，返回裁剪后的子范围及国家、ISP汇总
+// limit限制返回的子范围数量，汇总总是基于全部子范围
+func SummarizePrefix(provider QueryProvider, prefix netip.Prefix, limit int) (*CIDRReport, error) {
+	prefix = prefix.Masked()
+	first, last := prefix.Addr(), LastAddr(prefix)
+
+	report := &CIDRReport{
+		Prefix: prefix.String(),
+		Ranges: []*IPInfo{},
+	}
+	countries := newSummary()
+	isps := newSummary()
+
+	err := WalkRanges(provider, first, last, func(start, end netip.Addr, info *IPInfo) error {
+		if start.Less(first) {
+			start = first
+		}
+		if last.Less(end) {
+			end = last
+		}
+
+		size := rangeSize(start, end)
+		countries.add(info.Country, info.CountryCode, size)
+		isps.add(info.ISP, "", size)
+
+		report.TotalRanges++
+		if limit > 0 && len(report.Ranges) >= limit {
+			report.Truncated = true
+			return nil
+		}
+
+		info.IP = start.String()
+		info.Range = NewIPRange(start, end)
+		report.Ranges = append(report.Ranges, info)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	total := rangeSize(first, last)
+	report.Countries = countries.items(total)
+	report.ISPs = isps.items(total)
+	return report, nil
+}
+
+// rangeSize 返回[start, end]内的地址数量
+func rangeSize(start, end netip.Addr) *big.Int {
+	size := new(big.Int).Sub(new(big.Int).SetBytes(end.AsSlice()), new(big.Int).SetBytes(start.AsSlice()))
+	return size.Add(size, big.NewInt(1))
+}
+
+// summaryEntry 汇总累加项
+type summaryEntry struct {
+	code   string
+	ranges int
+	size   *big.Int
+}
+
+// summary 按名称累加IP段数量和地址数量
+type summary struct {
+	entries map[string]*summaryEntry
+}
+
+// newSummary 创建汇总
+func newSummary() *summary {
+	return &summary{entries: make(map[string]*summaryEntry)}
+}
+
+// add 累加一个IP段
+func (s *summary) add(name, code string, size *big.Int) {
+	if name == "" {
+		name = unknownName
+	}
+	entry, ok := s.entries[name]
+	if !ok {
+		entry = &summaryEntry{code: code, size: new(big.Int)}
+		s.entries[name] = entry
+	}
+	entry.ranges++
+	entry.size.Add(entry.size, size)
+}
+
+// items 按地址空间占比从大到小返回汇总结果
+func (s *summary) items(total *big.Int) []CIDRSummaryItem {
+	items := make([]CIDRSummaryItem, 0, len(s.entries))
+	for name, entry := range s.entries {
+		share, _ := new(big.Rat).SetFrac(entry.size, total).Float64()
+		items = append(items, CIDRSummaryItem{
+			Name:   name,
+			Code:   entry.code,
+			Ranges: entry.ranges,
+			Share:  share,
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Share != items[j].Share {
+			return items[i].Share > items[j].Share
+		}
+		return items[i].Name < items[j].Name
+	})
+	return items
+}
@@ -0,0 +1,165 @@
+package ipquery
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ushell/goip/pkg/logger"
+)
+
+// fileFingerprint 文件指纹
+type fileFingerprint struct {
+	size     int64
+	modTime  time.Time
+	checksum string
+}
+
+// FileWatcher 文件监视器，按固定间隔检查文件的修改时间、大小和SHA-256校验和，
+// 文件内容发生变化时触发回调
+type FileWatcher struct {
+	path     string
+	interval time.Duration
+	onChange func() error
+	logger   *logger.Logger
+
+	mu   sync.Mutex
+	last fileFingerprint
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewFileWatcher 创建新的文件监视器，以当前文件状态作为基准
+func NewFileWatcher(path string, interval time.Duration, onChange func() error, logger *logger.Logger) (*FileWatcher, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid watch interval: %s", interval)
+	}
+
+	w := &FileWatcher{
+		path:     path,
+		interval: interval,
+		onChange: onChange,
+		logger:   logger,
+		stop:     make(chan struct{}),
+	}
+
+	fp, err := fingerprintFile(path, fileFingerprint{})
+	if err != nil {
+		return nil, err
+	}
+	w.last = fp
+
+	return w, nil
+}
+
+// Start 启动后台检查
+func (w *FileWatcher) Start() {
+	w.wg.Add(1)
+	go w.run()
+}
+
+// Stop 停止后台检查
+func (w *FileWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+	w.wg.Wait()
+}
+
+// Check 立即检查文件是否变化，变化时执行回调
+// 回调失败时不更新基准，下次检查会重试
+func (w *FileWatcher) Check() (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	fp, err := fingerprintFile(w.path, w.last)
+	if err != nil {
+		return false, err
+	}
+
+	if fp.checksum == w.last.checksum {
+		// 内容未变化，仅刷新元数据
+		w.last = fp
+		return false, nil
+	}
+
+	if err := w.onChange(); err != nil {
+		return false, err
+	}
+
+	w.last = fp
+	return true, nil
+}
+
+// run 定时检查循环
+func (w *FileWatcher) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			changed, err := w.Check()
+			if err != nil {
+				w.logger.WithError(err).WithField("path", w.path).Error("文件变化处理失败")
+				continue
+			}
+			if changed {
+				w.logger.WithField("path", w.path).Info("文件已更新并重新加载")
+			}
+		}
+	}
+}
+
+// fingerprintFile 计算文件指纹
+// 修改时间和大小与上次一致时沿用上次的校验和，避免重复读取整个文件
+func fingerprintFile(path string, prev fileFingerprint) (fileFingerprint, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return fileFingerprint{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	fp := fileFingerprint{
+		size:    stat.Size(),
+		modTime: stat.ModTime(),
+	}
+
+	if prev.checksum != "" && fp.size == prev.size && fp.modTime.Equal(prev.modTime) {
+		fp.checksum = prev.checksum
+		return fp, nil
+	}
+
+	checksum, err := fileChecksum(path)
+	if err != nil {
+		return fileFingerprint{}, err
+	}
+	fp.checksum = checksum
+
+	return fp, nil
+}
+
+// fileChecksum 计算文件的SHA-256校验和
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,441 @@
+package ipquery
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	ip2region "github.com/lionsoul2014/ip2region/binding/golang/xdb"
+)
+
+// vectorIndexLength 向量索引总长度
+const vectorIndexLength = ip2region.VectorIndexRows * ip2region.VectorIndexCols * ip2region.VectorIndexSize
+
+// xdbSegment xdb段索引记录
+type xdbSegment struct {
+	startIP uint32
+	endIP   uint32
+	dataLen uint16
+	dataPtr uint32
+	ptr     uint32 // 段索引记录自身的偏移
+}
+
+// xdbDatabase ip2region xdb数据库读取器
+// 与上游Searcher不同，它能返回命中的IP段；所有读取都通过io.ReaderAt完成，
+// *os.File和bytes.Reader的ReadAt均可并发调用，因此同一个实例可被多个goroutine共享
+type xdbDatabase struct {
+	source      io.ReaderAt
+	file        *os.File // 文件和向量索引模式下的句柄
+	header      *ip2region.Header
+	vectorIndex []byte // 文件模式下为nil，每次查询从文件读取
+	mode        LoadMode
+	size        int64
+	runs        []xdbRun // 跨越多个段的合并范围，按地址排序
+}
+
+// xdbRun 相邻且区域数据相同的多个段合并后的范围
+type xdbRun struct {
+	start, end uint32
+}
+
+// openXDB 按加载模式打开xdb数据库
+func openXDB(path string, mode LoadMode) (*xdbDatabase, error) {
+	db := &xdbDatabase{mode: mode}
+
+	var size int64
+	switch mode {
+	case LoadModeMemory:
+		content, err := ip2region.LoadContentFromFile(path)
+		if err != nil {
+			return nil, err
+		}
+		db.source = bytes.NewReader(content)
+		size = int64(len(content))
+	default:
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		stat, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		db.file = f
+		db.source = f
+		size = stat.Size()
+	}
+
+	db.size = size
+	if err := db.loadHeader(size); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if mode != LoadModeFile {
+		db.vectorIndex = make([]byte, vectorIndexLength)
+		if err := db.read(ip2region.HeaderInfoLength, db.vectorIndex); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("read vector index: %w", err)
+		}
+	}
+
+	if err := db.loadRuns(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// loadHeader 读取并检查文件头，确保段索引位于文件范围内
+func (d *xdbDatabase) loadHeader(size int64) error {
+	if size < ip2region.HeaderInfoLength+vectorIndexLength {
+		return fmt.Errorf("invalid xdb file: size %d is too small", size)
+	}
+
+	buff := make([]byte, ip2region.HeaderInfoLength)
+	if err := d.read(0, buff); err != nil {
+		return fmt.Errorf("read header: %w", err)
+	}
+
+	header, err := ip2region.NewHeader(buff)
+	if err != nil {
+		return err
+	}
+
+	start, end := int64(header.StartIndexPtr), int64(header.EndIndexPtr)
+	if start < ip2region.HeaderInfoLength+vectorIndexLength || end < start ||
+		end+ip2region.SegmentIndexBlockSize > size ||
+		(end-start)%ip2region.SegmentIndexBlockSize != 0 {
+		return fmt.Errorf("invalid xdb file: segment index [%d, %d] out of range", start, end)
+	}
+
+	d.header = header
+	return nil
+}
+
+// read 从指定偏移读取完整的buff
+func (d *xdbDatabase) read(offset int64, buff []byte) error {
+	n, err := d.source.ReadAt(buff, offset)
+	if n == len(buff) {
+		return nil
+	}
+	if err == nil {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("read %d bytes at %d: %w", len(buff), offset, err)
+}
+
+// readSegment 读取指定偏移的段索引记录
+func (d *xdbDatabase) readSegment(ptr uint32) (xdbSegment, error) {
+	buff := make([]byte, ip2region.SegmentIndexBlockSize)
+	if err := d.read(int64(ptr), buff); err != nil {
+		return xdbSegment{}, err
+	}
+
+	return xdbSegment{
+		startIP: binary.LittleEndian.Uint32(buff),
+		endIP:   binary.LittleEndian.Uint32(buff[4:]),
+		dataLen: binary.LittleEndian.Uint16(buff[8:]),
+		dataPtr: binary.LittleEndian.Uint32(buff[10:]),
+		ptr:     ptr,
+	}, nil
+}
+
+// Search 查找包含指定IPv4地址的段，未找到时返回nil
+func (d *xdbDatabase) Search(ip uint32) (*xdbSegment, error) {
+	// 通过向量索引定位段索引的查找范围
+	il0 := (ip >> 24) & 0xFF
+	il1 := (ip >> 16) & 0xFF
+	idx := il0*ip2region.VectorIndexCols*ip2region.VectorIndexSize + il1*ip2region.VectorIndexSize
+
+	var vector []byte
+	if d.vectorIndex != nil {
+		vector = d.vectorIndex[idx : idx+ip2region.VectorIndexSize]
+	} else {
+		vector = make([]byte, ip2region.VectorIndexSize)
+		if err := d.read(int64(ip2region.HeaderInfoLength+idx), vector); err != nil {
+			return nil, fmt.Errorf("read vector index block: %w", err)
+		}
+	}
+
+	sPtr := binary.LittleEndian.Uint32(vector)
+	ePtr := binary.LittleEndian.Uint32(vector[4:])
+	if sPtr == 0 || ePtr < sPtr {
+		return nil, nil
+	}
+
+	// 二分查找段索引，ePtr可能指向最后一条记录之后，因此需要限制在段索引范围内
+	l, h := 0, int((ePtr-sPtr)/ip2region.SegmentIndexBlockSize)
+	if last := int((d.header.EndIndexPtr - sPtr) / ip2region.SegmentIndexBlockSize); h > last {
+		h = last
+	}
+	for l <= h {
+		m := (l + h) >> 1
+		seg, err := d.readSegment(sPtr + uint32(m*ip2region.SegmentIndexBlockSize))
+		if err != nil {
+			return nil, fmt.Errorf("read segment index: %w", err)
+		}
+
+		switch {
+		case ip < seg.startIP:
+			h = m - 1
+		case ip > seg.endIP:
+			l = m + 1
+		default:
+			return &seg, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Region 读取段对应的区域数据
+func (d *xdbDatabase) Region(seg *xdbSegment) (string, error) {
+	if seg.dataLen == 0 {
+		return "", nil
+	}
+
+	buff := make([]byte, seg.dataLen)
+	if err := d.read(int64(seg.dataPtr), buff); err != nil {
+		return "", fmt.Errorf("read region: %w", err)
+	}
+	return string(buff), nil
+}
+
+// loadRuns 遍历一次段索引，记录由多个段合并而成的范围
+// 生成xdb时同一区域的连续地址会在/16边界处被拆分为多个段，合并后才是真实的网段范围。
+// 大多数范围只有一个段，只记录需要合并的范围，查询时无需再读取相邻的段
+func (d *xdbDatabase) loadRuns() error {
+	var runs []xdbRun
+	var current xdbSegment
+	count := 0 // current合并的段数
+
+	err := d.scan(0, func(seg *xdbSegment) error {
+		if count > 0 && current.endIP+1 == seg.startIP && sameRegion(&current, seg) {
+			current.endIP = seg.endIP
+			count++
+			return nil
+		}
+		if count > 1 {
+			runs = append(runs, xdbRun{start: current.startIP, end: current.endIP})
+		}
+		current, count = *seg, 1
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if count > 1 {
+		runs = append(runs, xdbRun{start: current.startIP, end: current.endIP})
+	}
+
+	d.runs = runs
+	return nil
+}
+
+// sameRegion 判断两个段是否指向同一份区域数据
+func sameRegion(a, b *xdbSegment) bool {
+	return a.dataPtr == b.dataPtr && a.dataLen == b.dataLen
+}
+
+// ExpandRange 返回与该段相邻且区域数据相同的完整IP范围，使用打开时计算的合并范围，不读取文件
+func (d *xdbDatabase) ExpandRange(seg *xdbSegment) (uint32, uint32) {
+	i := sort.Search(len(d.runs), func(i int) bool {
+		return d.runs[i].end >= seg.startIP
+	})
+	if i < len(d.runs) && d.runs[i].start <= seg.startIP {
+		return d.runs[i].start, d.runs[i].end
+	}
+	return seg.startIP, seg.endIP
+}
+
+// segmentCount 返回段索引记录数
+func (d *xdbDatabase) segmentCount() uint32 {
+	return (d.header.EndIndexPtr-d.header.StartIndexPtr)/ip2region.SegmentIndexBlockSize + 1
+}
+
+// firstSegment 二分查找第一个结束地址不小于ip的段索引序号
+func (d *xdbDatabase) firstSegment(ip uint32) (uint32, error) {
+	l, h := uint32(0), d.segmentCount()
+	for l < h {
+		m := l + (h-l)/2
+		seg, err := d.readSegment(d.header.StartIndexPtr + m*ip2region.SegmentIndexBlockSize)
+		if err != nil {
+			return 0, err
+		}
+		if seg.endIP < ip {
+			l = m + 1
+		} else {
+			h = m
+		}
+	}
+	return l, nil
+}
+
+// walkBatch 遍历时每次读取的段索引记录数
+const walkBatch = 4096
+
+// errStopScan 由scan的回调返回，提前结束遍历
+var errStopScan = errors.New("stop scan")
+
+// scan 从第first个段开始按顺序遍历段索引，每次批量读取walkBatch条记录
+func (d *xdbDatabase) scan(first uint32, fn func(seg *xdbSegment) error) error {
+	buff := make([]byte, walkBatch*ip2region.SegmentIndexBlockSize)
+	for i := first; i < d.segmentCount(); {
+		n := d.segmentCount() - i
+		if n > walkBatch {
+			n = walkBatch
+		}
+		ptr := d.header.StartIndexPtr + i*ip2region.SegmentIndexBlockSize
+		chunk := buff[:n*ip2region.SegmentIndexBlockSize]
+		if err := d.read(int64(ptr), chunk); err != nil {
+			return fmt.Errorf("read segment index: %w", err)
+		}
+
+		for j := uint32(0); j < n; j++ {
+			b := chunk[j*ip2region.SegmentIndexBlockSize:]
+			seg := xdbSegment{
+				startIP: binary.LittleEndian.Uint32(b),
+				endIP:   binary.LittleEndian.Uint32(b[4:]),
+				dataLen: binary.LittleEndian.Uint16(b[8:]),
+				dataPtr: binary.LittleEndian.Uint32(b[10:]),
+				ptr:     ptr + j*ip2region.SegmentIndexBlockSize,
+			}
+			if err := fn(&seg); err != nil {
+				if err == errStopScan {
+					return nil
+				}
+				return err
+			}
+		}
+		i += n
+	}
+	return nil
+}
+
+// Walk 按地址顺序遍历与[start, end]重叠的IP段
+// 相邻且区域数据相同的段合并后回调，回调得到的是合并后的完整范围，调用方按需裁剪
+func (d *xdbDatabase) Walk(start, end uint32, fn func(start, end uint32, region string) error) error {
+	first, err := d.firstSegment(start)
+	if err != nil {
+		return fmt.Errorf("read segment index: %w", err)
+	}
+
+	emit := func(seg *xdbSegment) error {
+		region, err := d.Region(seg)
+		if err != nil {
+			return err
+		}
+		return fn(seg.startIP, seg.endIP, region)
+	}
+
+	var current *xdbSegment
+	var walkErr error
+	err = d.scan(first, func(seg *xdbSegment) error {
+		if current == nil {
+			if seg.startIP > end {
+				return errStopScan
+			}
+			// 第一个段向前合并，得到完整的起始范围
+			seg.startIP, _ = d.ExpandRange(seg)
+			current = seg
+			return nil
+		}
+		if current.endIP+1 == seg.startIP && sameRegion(current, seg) {
+			current.endIP = seg.endIP
+			return nil
+		}
+		// 当前范围已经结束，超出遍历范围的段不再处理
+		if walkErr = emit(current); walkErr != nil {
+			return errStopScan
+		}
+		if seg.startIP > end {
+			current = nil
+			return errStopScan
+		}
+		current = seg
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if walkErr != nil || current == nil {
+		return walkErr
+	}
+	return emit(current)
+}
+
+// xdbVersion 支持的xdb结构版本
+const xdbVersion = 2
+
+// Verify 完整检查数据库结构：文件头的版本和索引策略、向量索引指向段索引区、
+// 段按地址顺序连续覆盖整个IPv4空间且区域数据位于数据区内
+// 截断或损坏的文件在打开时不一定能发现，查询时才会出错或返回错误数据
+func (d *xdbDatabase) Verify() error {
+	if d.header.Version != xdbVersion {
+		return fmt.Errorf("unsupported xdb version: %d", d.header.Version)
+	}
+	if d.header.IndexPolicy != ip2region.VectorIndexPolicy && d.header.IndexPolicy != ip2region.BTreeIndexPolicy {
+		return fmt.Errorf("unknown xdb index policy: %d", d.header.IndexPolicy)
+	}
+
+	vectorIndex := d.vectorIndex
+	if vectorIndex == nil {
+		vectorIndex = make([]byte, vectorIndexLength)
+		if err := d.read(ip2region.HeaderInfoLength, vectorIndex); err != nil {
+			return fmt.Errorf("read vector index: %w", err)
+		}
+	}
+	for i := 0; i < len(vectorIndex); i += ip2region.VectorIndexSize {
+		sPtr := binary.LittleEndian.Uint32(vectorIndex[i:])
+		ePtr := binary.LittleEndian.Uint32(vectorIndex[i+4:])
+		if sPtr == 0 && ePtr == 0 {
+			continue
+		}
+		// 生成器写入的ePtr为该区间最后一个段之后的位置
+		if sPtr < d.header.StartIndexPtr || ePtr > d.header.EndIndexPtr+ip2region.SegmentIndexBlockSize || sPtr > ePtr {
+			return fmt.Errorf("vector index entry %d [%d, %d] out of segment index", i/ip2region.VectorIndexSize, sPtr, ePtr)
+		}
+	}
+
+	dataStart := uint64(ip2region.HeaderInfoLength + vectorIndexLength)
+	dataEnd := uint64(d.header.StartIndexPtr)
+	next := uint64(0)
+
+	i := 0
+	err := d.scan(0, func(seg *xdbSegment) error {
+		dataLen, dataPtr := uint64(seg.dataLen), uint64(seg.dataPtr)
+		if uint64(seg.startIP) != next || seg.endIP < seg.startIP {
+			return fmt.Errorf("segment %d [%s, %s] is not contiguous",
+				i, uint32ToAddr(seg.startIP), uint32ToAddr(seg.endIP))
+		}
+		if dataLen > 0 && (dataPtr < dataStart || dataPtr+dataLen > dataEnd) {
+			return fmt.Errorf("segment %d region data [%d, %d) out of data section", i, dataPtr, dataPtr+dataLen)
+		}
+		next = uint64(seg.endIP) + 1
+		i++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if next != 1<<32 {
+		return fmt.Errorf("segment index ends at %s, expected 255.255.255.255", uint32ToAddr(uint32(next-1)))
+	}
+	return nil
+}
+
+// Close 关闭数据库文件
+func (d *xdbDatabase) Close() error {
+	if d.file != nil {
+		return d.file.Close()
+	}
+	return nil
+}
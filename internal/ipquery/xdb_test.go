@@ -0,0 +1,377 @@
+package ipquery
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"net/netip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ip2region "github.com/lionsoul2014/ip2region/binding/golang/xdb"
+)
+
+// xdbTestRange 测试数据库中的一个IP范围
+type xdbTestRange struct {
+	start, end string
+	region     string
+}
+
+// xdbTestRanges 连续覆盖整个IPv4空间的测试数据：
+// 多个范围跨越/16边界，生成时会被拆分为多个段；
+// 32.0.0.0和114.115.0.0开始的两个范围区域相同但不相邻，不能合并
+var xdbTestRanges = []xdbTestRange{
+	{"0.0.0.0", "0.255.255.255", "0|0|0|内网IP|内网IP"},
+	{"1.0.0.0", "1.0.0.255", "澳大利亚|0|0|0|0"},
+	{"1.0.1.0", "1.3.255.255", "中国|0|福建省|福州市|电信"},
+	{"1.4.0.0", "31.255.255.255", "美国|0|0|0|0"},
+	{"32.0.0.0", "114.113.255.255", "中国|0|0|0|0"},
+	{"114.114.0.0", "114.114.255.255", "中国|0|江苏省|南京市|电信"},
+	{"114.115.0.0", "223.255.255.255", "中国|0|0|0|0"},
+	{"224.0.0.0", "255.255.255.255", "0|0|0|0|0"},
+}
+
+// buildTestXDB 按ip2region生成器的格式生成xdb数据库：
+// 相同的区域数据只写一次，范围在/16边界处拆分为段，向量索引的结束指针指向最后一个段之后
+func buildTestXDB(t *testing.T, ranges []xdbTestRange) []byte {
+	t.Helper()
+
+	dataStart := ip2region.HeaderInfoLength + vectorIndexLength
+	var data []byte
+	regionPtr := make(map[string]uint32)
+	type segment struct {
+		start, end uint32
+		ptr        uint32
+		length     uint16
+	}
+	var segments []segment
+	for _, r := range ranges {
+		ptr, ok := regionPtr[r.region]
+		if !ok {
+			ptr = uint32(dataStart + len(data))
+			regionPtr[r.region] = ptr
+			data = append(data, r.region...)
+		}
+
+		start := addrToUint32(netip.MustParseAddr(r.start))
+		end := addrToUint32(netip.MustParseAddr(r.end))
+		for s := start; ; {
+			e := s | 0xFFFF
+			if e > end {
+				e = end
+			}
+			segments = append(segments, segment{s, e, ptr, uint16(len(r.region))})
+			if e == end {
+				break
+			}
+			s = e + 1
+		}
+	}
+
+	indexStart := uint32(dataStart + len(data))
+	content := make([]byte, int(indexStart)+len(segments)*ip2region.SegmentIndexBlockSize)
+	binary.LittleEndian.PutUint16(content, xdbVersion)
+	binary.LittleEndian.PutUint16(content[2:], uint16(ip2region.VectorIndexPolicy))
+	binary.LittleEndian.PutUint32(content[4:], 1700000000)
+	binary.LittleEndian.PutUint32(content[8:], indexStart)
+	binary.LittleEndian.PutUint32(content[12:], indexStart+uint32(len(segments)-1)*ip2region.SegmentIndexBlockSize)
+	copy(content[dataStart:], data)
+
+	for i, seg := range segments {
+		ptr := indexStart + uint32(i*ip2region.SegmentIndexBlockSize)
+		b := content[ptr:]
+		binary.LittleEndian.PutUint32(b, seg.start)
+		binary.LittleEndian.PutUint32(b[4:], seg.end)
+		binary.LittleEndian.PutUint16(b[8:], seg.length)
+		binary.LittleEndian.PutUint32(b[10:], seg.ptr)
+
+		vector := content[ip2region.HeaderInfoLength+int(seg.start>>16)*ip2region.VectorIndexSize:]
+		if binary.LittleEndian.Uint32(vector) == 0 {
+			binary.LittleEndian.PutUint32(vector, ptr)
+		}
+		binary.LittleEndian.PutUint32(vector[4:], ptr+ip2region.SegmentIndexBlockSize)
+	}
+	return content
+}
+
+// writeTestXDB 将测试数据库写入临时文件
+func writeTestXDB(t *testing.T, content []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ip2region.xdb")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// xdbLoadModes 需要覆盖的加载模式，各模式使用不同的读取路径
+var xdbLoadModes = []LoadMode{LoadModeFile, LoadModeVector, LoadModeMemory}
+
+// xdbProbeIPs 返回各范围边界、/16边界附近的地址以及固定种子生成的随机地址
+func xdbProbeIPs(ranges []xdbTestRange, random int) []uint32 {
+	var ips []uint32
+	for _, r := range ranges {
+		start := addrToUint32(netip.MustParseAddr(r.start))
+		end := addrToUint32(netip.MustParseAddr(r.end))
+		ips = append(ips, start, start+1, end-1, end)
+	}
+	for _, b := range []uint32{1<<16 - 1, 1 << 16, 2<<16 - 1, 2 << 16, 0xF0000000 - 1, 0xF0000000, 0xFFFF0000, 0xFFFFFFFF} {
+		ips = append(ips, b)
+	}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < random; i++ {
+		ips = append(ips, rng.Uint32())
+	}
+	return ips
+}
+
+// upstreamRegion 使用上游Searcher查询区域数据
+func upstreamRegion(t *testing.T, searcher *ip2region.Searcher, ip uint32) string {
+	t.Helper()
+	region, err := searcher.Search(ip)
+	if err != nil {
+		t.Fatalf("upstream search %s: %v", uint32ToAddr(ip), err)
+	}
+	return region
+}
+
+// assertMatchesUpstream 比较各加载模式下的查询结果与上游Searcher的结果，并检查返回的范围是最大范围
+func assertMatchesUpstream(t *testing.T, path string, ips []uint32) {
+	t.Helper()
+	searcher, err := ip2region.NewWithFileOnly(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer searcher.Close()
+
+	for _, mode := range xdbLoadModes {
+		t.Run(string(mode), func(t *testing.T) {
+			provider, err := NewIP2RegionProvider(IP2RegionOptions{Path: path, LoadMode: mode})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer provider.Close()
+
+			for _, ip := range ips {
+				want := upstreamRegion(t, searcher, ip)
+				region, ipRange, err := provider.search(uint32ToAddr(ip).String())
+				if err != nil {
+					t.Fatalf("search %s: %v", uint32ToAddr(ip), err)
+				}
+				if region != want {
+					t.Fatalf("search %s = %q, upstream %q", uint32ToAddr(ip), region, want)
+				}
+
+				start := addrToUint32(netip.MustParseAddr(ipRange.Start))
+				end := addrToUint32(netip.MustParseAddr(ipRange.End))
+				if ip < start || ip > end {
+					t.Fatalf("%s: range %s-%s does not contain the address", uint32ToAddr(ip), ipRange.Start, ipRange.End)
+				}
+				if upstreamRegion(t, searcher, start) != want || upstreamRegion(t, searcher, end) != want {
+					t.Fatalf("%s: range %s-%s crosses a region boundary", uint32ToAddr(ip), ipRange.Start, ipRange.End)
+				}
+				if start > 0 && upstreamRegion(t, searcher, start-1) == want {
+					t.Fatalf("%s: range %s-%s is not maximal, %s has the same region",
+						uint32ToAddr(ip), ipRange.Start, ipRange.End, uint32ToAddr(start-1))
+				}
+				if end < 0xFFFFFFFF && upstreamRegion(t, searcher, end+1) == want {
+					t.Fatalf("%s: range %s-%s is not maximal, %s has the same region",
+						uint32ToAddr(ip), ipRange.Start, ipRange.End, uint32ToAddr(end+1))
+				}
+			}
+		})
+	}
+}
+
+func TestXDBMatchesUpstreamSearcher(t *testing.T) {
+	path := writeTestXDB(t, buildTestXDB(t, xdbTestRanges))
+	assertMatchesUpstream(t, path, xdbProbeIPs(xdbTestRanges, 5000))
+}
+
+// TestXDBMatchesUpstreamOnBundledDatabase 使用上游仓库附带的ip2region.xdb进行比较，模块缓存中没有该文件时跳过
+func TestXDBMatchesUpstreamOnBundledDatabase(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping bundled database comparison in short mode")
+	}
+	out, err := exec.Command("go", "list", "-m", "-f", "{{.Dir}}", "github.com/lionsoul2014/ip2region").Output()
+	if err != nil {
+		t.Skipf("ip2region module not available: %v", err)
+	}
+	path := filepath.Join(strings.TrimSpace(string(out)), "data", "ip2region.xdb")
+	if _, err := os.Stat(path); err != nil {
+		t.Skipf("bundled database not available: %v", err)
+	}
+
+	assertMatchesUpstream(t, path, xdbProbeIPs(nil, 20000))
+}
+
+func TestXDBRangeAtSegmentBoundaries(t *testing.T) {
+	path := writeTestXDB(t, buildTestXDB(t, xdbTestRanges))
+
+	tests := []struct {
+		ip         string
+		start, end string
+		cidrs      []string
+	}{
+		// 224.0.0.0/3在生成时被拆分为8192个段
+		{"240.0.0.0", "224.0.0.0", "255.255.255.255", []string{"224.0.0.0/3"}},
+		{"255.255.255.255", "224.0.0.0", "255.255.255.255", []string{"224.0.0.0/3"}},
+		{"1.1.255.255", "1.0.1.0", "1.3.255.255", []string{"1.0.1.0/24", "1.0.2.0/23", "1.0.4.0/22", "1.0.8.0/21",
+			"1.0.16.0/20", "1.0.32.0/19", "1.0.64.0/18", "1.0.128.0/17", "1.1.0.0/16", "1.2.0.0/15"}},
+		{"1.2.0.0", "1.0.1.0", "1.3.255.255", nil},
+		// 超过一次批量读取的段数
+		{"20.0.0.1", "1.4.0.0", "31.255.255.255", nil},
+		{"114.113.255.255", "32.0.0.0", "114.113.255.255", nil},
+		{"114.114.0.0", "114.114.0.0", "114.114.255.255", []string{"114.114.0.0/16"}},
+		// 与32.0.0.0开始的范围区域数据相同，但被114.114.0.0/16隔开
+		{"114.115.0.0", "114.115.0.0", "223.255.255.255", nil},
+		{"0.0.0.0", "0.0.0.0", "0.255.255.255", []string{"0.0.0.0/8"}},
+	}
+
+	for _, mode := range xdbLoadModes {
+		provider, err := NewIP2RegionProvider(IP2RegionOptions{Path: path, LoadMode: mode})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tt := range tests {
+			info, err := provider.Query(tt.ip)
+			if err != nil || !info.IsValid {
+				t.Fatalf("%s %s: Query = %+v, %v", mode, tt.ip, info, err)
+			}
+			if info.Range.Start != tt.start || info.Range.End != tt.end {
+				t.Errorf("%s %s: range = %s-%s, want %s-%s", mode, tt.ip, info.Range.Start, info.Range.End, tt.start, tt.end)
+			}
+			if tt.cidrs != nil && strings.Join(info.Range.CIDRs, ",") != strings.Join(tt.cidrs, ",") {
+				t.Errorf("%s %s: cidrs = %v, want %v", mode, tt.ip, info.Range.CIDRs, tt.cidrs)
+			}
+		}
+		provider.Close()
+	}
+}
+
+func TestXDBWalk(t *testing.T) {
+	path := writeTestXDB(t, buildTestXDB(t, xdbTestRanges))
+	db, err := openXDB(path, LoadModeFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	walk := func(start, end string) []xdbTestRange {
+		var got []xdbTestRange
+		err := db.Walk(addrToUint32(netip.MustParseAddr(start)), addrToUint32(netip.MustParseAddr(end)),
+			func(s, e uint32, region string) error {
+				got = append(got, xdbTestRange{uint32ToAddr(s).String(), uint32ToAddr(e).String(), region})
+				return nil
+			})
+		if err != nil {
+			t.Fatalf("Walk %s-%s: %v", start, end, err)
+		}
+		return got
+	}
+
+	if got := walk("0.0.0.0", "255.255.255.255"); !equalXDBRanges(got, xdbTestRanges) {
+		t.Errorf("Walk over all addresses = %v, want %v", got, xdbTestRanges)
+	}
+
+	// 回调得到完整的合并范围，由调用方裁剪
+	got := walk("114.113.0.5", "114.114.0.0")
+	if !equalXDBRanges(got, xdbTestRanges[4:6]) {
+		t.Errorf("Walk 114.113.0.5-114.114.0.0 = %v, want %v", got, xdbTestRanges[4:6])
+	}
+	got = walk("240.0.0.0", "240.0.0.0")
+	if !equalXDBRanges(got, xdbTestRanges[7:]) {
+		t.Errorf("Walk 240.0.0.0 = %v, want %v", got, xdbTestRanges[7:])
+	}
+}
+
+func equalXDBRanges(a, b []xdbTestRange) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestXDBVerifyRejectsCorruptFiles(t *testing.T) {
+	valid := buildTestXDB(t, xdbTestRanges)
+	indexStart := binary.LittleEndian.Uint32(valid[8:])
+	indexEnd := binary.LittleEndian.Uint32(valid[12:])
+	segment := func(content []byte, i int) []byte {
+		return content[int(indexStart)+i*ip2region.SegmentIndexBlockSize:]
+	}
+
+	tests := []struct {
+		name    string
+		corrupt func(content []byte) []byte
+		wantErr string
+	}{
+		{"truncated segment index", func(content []byte) []byte {
+			// 截断段索引的后半部分，文件头中的结束指针随之修改，打开时无法发现
+			end := indexStart + (indexEnd-indexStart)/2/ip2region.SegmentIndexBlockSize*ip2region.SegmentIndexBlockSize
+			binary.LittleEndian.PutUint32(content[12:], end)
+			return content[:end+ip2region.SegmentIndexBlockSize]
+		}, "out of segment index"},
+		{"segment index ends early", func(content []byte) []byte {
+			binary.LittleEndian.PutUint32(content[indexEnd+4:], 0xFFFFFFFE)
+			return content
+		}, "expected 255.255.255.255"},
+		{"segment gap", func(content []byte) []byte {
+			b := segment(content, 100)
+			binary.LittleEndian.PutUint32(b, binary.LittleEndian.Uint32(b)+1)
+			return content
+		}, "is not contiguous"},
+		{"region outside data section", func(content []byte) []byte {
+			binary.LittleEndian.PutUint32(segment(content, 10)[10:], indexStart)
+			return content
+		}, "out of data section"},
+		{"vector index out of range", func(content []byte) []byte {
+			binary.LittleEndian.PutUint32(content[ip2region.HeaderInfoLength+4:], indexEnd+1000)
+			return content
+		}, "out of segment index"},
+		{"unsupported version", func(content []byte) []byte {
+			binary.LittleEndian.PutUint16(content, 3)
+			return content
+		}, "unsupported xdb version"},
+	}
+
+	path := writeTestXDB(t, valid)
+	provider, err := NewIP2RegionProvider(IP2RegionOptions{Path: path, LoadMode: LoadModeFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := provider.Verify(); err != nil {
+		t.Fatalf("Verify rejected a valid file: %v", err)
+	}
+	provider.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := tt.corrupt(append([]byte(nil), valid...))
+			path := writeTestXDB(t, content)
+			for _, mode := range xdbLoadModes {
+				provider, err := NewIP2RegionProvider(IP2RegionOptions{Path: path, LoadMode: mode})
+				if err != nil {
+					t.Fatalf("%s: open: %v", mode, err)
+				}
+				err = provider.Verify()
+				provider.Close()
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("%s: Verify = %v, want %q", mode, err, tt.wantErr)
+				}
+			}
+		})
+	}
+
+	// 截断到段索引之前的文件在打开时即被拒绝
+	path = writeTestXDB(t, valid[:len(valid)-ip2region.SegmentIndexBlockSize])
+	if _, err := NewIP2RegionProvider(IP2RegionOptions{Path: path, LoadMode: LoadModeFile}); err == nil {
+		t.Error("opened a file whose segment index is cut short")
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"crypto/subtle"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ushell/goip/internal/ipquery"
+	"github.com/ushell/goip/pkg/errors"
+)
+
+// CacheReport 缓存统计及命中率
+type CacheReport struct {
+	ipquery.CacheStats
+	HitRate        float64 `json:"hit_rate"`        // 命中次数占缓存读取次数的比例
+	MissRate       float64 `json:"miss_rate"`       // 未命中次数占缓存读取次数的比例
+	EvictionRate   float64 `json:"eviction_rate"`   // 自启动以来平均每秒淘汰的条目数
+	CollapsedCount int64   `json:"collapsed_count"` // 与进行中的相同查询合并的次数
+}
+
+// AdminEnabled 判断是否配置了管理接口令牌，未配置时不提供管理接口
+func (s *IPService) AdminEnabled() bool {
+	return s.config.Admin.Token != ""
+}
+
+// AuthorizeAdmin 校验管理接口令牌，未配置admin.token时拒绝所有请求
+func (s *IPService) AuthorizeAdmin(token string) error {
+	if !s.AdminEnabled() {
+		return errors.New(errors.ErrCodeForbidden, "管理接口未启用")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.config.Admin.Token)) != 1 {
+		return errors.New(errors.ErrCodeUnauthorized, "管理接口未授权")
+	}
+	return nil
+}
+
+// enabledCache 返回已启用的缓存
+func (s *IPService) enabledCache() (ipquery.Cache, error) {
+	if s.cache == nil {
+		return nil, errors.New(errors.ErrCodeCacheError, "缓存未启用")
+	}
+	return s.cache, nil
+}
+
+// CacheStats 获取缓存统计及命中率，包括需要遍历Redis键空间才能得到的条目数
+func (s *IPService) CacheStats() (*CacheReport, error) {
+	cache, err := s.enabledCache()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := cache.Stats()
+	// Stats不统计Redis中的条目数，管理接口按需遍历键空间计数
+	stats.Size = cache.Size()
+	report := &CacheReport{
+		CacheStats:     stats,
+		CollapsedCount: atomic.LoadInt64(&s.collapsedCount),
+	}
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		report.HitRate = float64(stats.Hits) / float64(lookups)
+		report.MissRate = float64(stats.Misses) / float64(lookups)
+	}
+	if uptime := time.Since(s.startTime).Seconds(); uptime > 0 {
+		report.EvictionRate = float64(cacheEvictions(stats)) / uptime
+	}
+	return report, nil
+}
+
+// cacheEvictions 返回各级缓存淘汰的条目总数
+func cacheEvictions(stats ipquery.CacheStats) uint64 {
+	evictions := stats.Evictions
+	for _, tier := range stats.Tiers {
+		evictions += cacheEvictions(tier)
+	}
+	return evictions
+}
+
+// cacheKeys 返回IP可能使用的缓存键：请求中的原始写法以及规范写法
+func cacheKeys(ip string) ([]string, error) {
+	ip = strings.TrimSpace(ip)
+	if !ipquery.ValidateIP(ip) {
+		return nil, errors.New(errors.ErrCodeInvalidRequest, "无效的IP地址格式")
+	}
+
+	keys := []string{ip}
+	if addr, err := netip.ParseAddr(ip); err == nil && addr.String() != ip {
+		keys = append(keys, addr.String())
+	}
+	return keys, nil
+}
+
+// InspectCache 查看IP的缓存条目及其过期时间，未缓存时返回空列表
+func (s *IPService) InspectCache(ip string) ([]ipquery.CachedEntry, error) {
+	cache, err := s.enabledCache()
+	if err != nil {
+		return nil, err
+	}
+	keys, err := cacheKeys(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []ipquery.CachedEntry{}
+	for _, key := range keys {
+		entries = append(entries, cache.Inspect(key)...)
+	}
+	return entries, nil
+}
+
+// DeleteCacheEntry 删除IP的缓存条目
+func (s *IPService) DeleteCacheEntry(ip string) error {
+	cache, err := s.enabledCache()
+	if err != nil {
+		return err
+	}
+	keys, err := cacheKeys(ip)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := cache.Delete(key); err != nil {
+			return errors.NewWithError(errors.ErrCodeCacheUnavailable, "缓存后端不可用", err)
+		}
+	}
+	s.logger.WithField("ip", ip).Info("删除IP缓存成功")
+	return nil
+}
+
+// PurgeCache 删除网段内所有IP的缓存条目，返回删除的条目数
+func (s *IPService) PurgeCache(cidr string) (int, error) {
+	cache, err := s.enabledCache()
+	if err != nil {
+		return 0, err
+	}
+	prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+	if err != nil {
+		return 0, errors.New(errors.ErrCodeInvalidRequest, "无效的网段格式")
+	}
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+
+	purged, err := cache.PurgePrefix(prefix.Masked())
+	if err != nil {
+		return purged, errors.NewWithError(errors.ErrCodeCacheUnavailable, "缓存后端不可用", err)
+	}
+	s.logger.WithField("prefix", prefix.Masked().String()).WithField("count", purged).Info("清除网段缓存成功")
+	return purged, nil
+}
+
+// FlushCache 清空缓存
+func (s *IPService) FlushCache() error {
+	cache, err := s.enabledCache()
+	if err != nil {
+		return err
+	}
+
+	if err := cache.Clear(); err != nil {
+		return errors.NewWithError(errors.ErrCodeCacheUnavailable, "缓存后端不可用", err)
+	}
+	s.logger.Info("清空缓存成功")
+	return nil
+}
@@ -1,6 +1,11 @@
 package service
 
 import (
+	stderrors "errors"
+	"io"
+	"net/netip"
+	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -8,38 +13,262 @@ import (
 	"github.com/ushell/goip/internal/ipquery"
 	"github.com/ushell/goip/pkg/errors"
 	"github.com/ushell/goip/pkg/logger"
+	"golang.org/x/sync/singleflight"
 )
 
+// BuildInfo 服务的构建信息，由main包在编译时通过-ldflags注入
+type BuildInfo struct {
+	Version   string
+	BuildTime string
+	GitCommit string
+}
+
 // IPService IP查询服务
 type IPService struct {
-	provider   ipquery.QueryProvider
-	cache      *ipquery.MemoryCache
+	provider  *ipquery.ReloadableProvider
+	database  atomic.Pointer[ipquery.DatabaseInfo]
+	build     BuildInfo
+	overrides *ipquery.OverrideProvider
+	enrichers []ipquery.Enricher
+	watchers  []*ipquery.FileWatcher
+	fetcher   *ipquery.RemoteFetcher
+	cache     ipquery.Cache
+	flight    singleflight.Group
+	// reloads 覆盖表和增强数据的重新加载次数，与数据库版本共同构成数据版本
+	reloads    atomic.Uint64
 	config     *config.Config
 	logger     *logger.Logger
 	queryCount int64
-	startTime  time.Time
+	// collapsedCount 与进行中的相同查询合并、未单独查询数据源的次数
+	collapsedCount int64
+	startTime      time.Time
 }
 
 // NewIPService 创建新的IP服务
 func NewIPService(config *config.Config, logger *logger.Logger) (*IPService, error) {
-	var provider ipquery.QueryProvider
-	provider, err := ipquery.NewIP2RegionProvider(config.IPDatabase.Path)
+	provider, err := newProvider(config)
 	if err != nil {
 		return nil, errors.NewWithError(errors.ErrCodeInternalError, "初始化IP查询提供者失败", err)
 	}
+	if config.IPDatabase.PoolSize > 0 {
+		logger.WithField("pool_size", config.IPDatabase.PoolSize).Warn("ip_database.pool_size已废弃，该设置将被忽略")
+	}
 
-	var cache *ipquery.MemoryCache
+	var cache ipquery.Cache
 	if config.Cache.Enabled {
-		cache = ipquery.NewMemoryCache(config.Cache.TTL)
+		cache, err = ipquery.NewCache(CacheOptions(config.Cache), logger)
+		if err != nil {
+			provider.Close()
+			return nil, errors.NewWithError(errors.ErrCodeInternalError, "初始化缓存失败", err)
+		}
 	}
 
-	return &IPService{
-		provider:  provider,
+	reloadable := ipquery.NewReloadableProvider(provider)
+	overrides, err := ipquery.NewOverrideProvider(reloadable, config.Overrides.Path)
+	if err != nil {
+		reloadable.Close()
+		if cache != nil {
+			cache.Close()
+		}
+		return nil, errors.NewWithError(errors.ErrCodeInternalError, "加载CIDR覆盖表失败", err)
+	}
+
+	s := &IPService{
+		provider:  reloadable,
+		overrides: overrides,
 		cache:     cache,
 		config:    config,
 		logger:    logger,
+		build:     BuildInfo{Version: "dev", BuildTime: "unknown", GitCommit: "unknown"},
 		startTime: time.Now(),
-	}, nil
+	}
+	s.database.Store(ipquery.DescribeDatabase(DatabaseOptions(config.IPDatabase), provider))
+
+	if err := s.initEnrichers(); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	// 启动数据库自动重载
+	if config.IPDatabase.AutoReload && config.IPDatabase.ReloadInterval > 0 {
+		paths, err := ipquery.DatabasePaths(DatabaseOptions(config.IPDatabase))
+		if err != nil {
+			s.Close()
+			return nil, errors.NewWithError(errors.ErrCodeDatabaseError, "初始化数据库监视器失败", err)
+		}
+		for _, path := range paths {
+			if config.IPDatabase.Type == "remote" && path == config.IPDatabase.Path {
+				// 下载的数据库由下载器在替换后触发重新加载
+				continue
+			}
+			watcher, err := ipquery.NewFileWatcher(path, config.IPDatabase.ReloadInterval, s.ReloadDatabase, logger)
+			if err != nil {
+				s.Close()
+				return nil, errors.NewWithError(errors.ErrCodeDatabaseError, "初始化数据库监视器失败", err)
+			}
+			watcher.Start()
+			s.watchers = append(s.watchers, watcher)
+		}
+	}
+
+	// 启动远程数据库下载
+	if config.IPDatabase.Type == "remote" {
+		fetcher, err := ipquery.NewDatabaseFetcher(DatabaseOptions(config.IPDatabase))
+		if err != nil {
+			s.Close()
+			return nil, errors.NewWithError(errors.ErrCodeDatabaseError, "初始化远程数据库下载失败", err)
+		}
+		if !fetcher.Verified() {
+			logger.WithField("url", config.IPDatabase.Remote.URL).
+				Warn("ip_database.remote未配置sha256、checksum_url或public_key，下载的数据库将不经校验直接使用")
+		}
+		fetcher.Start(config.IPDatabase.Remote.Interval, s.ReloadDatabase, logger)
+		s.fetcher = fetcher
+	}
+
+	// 启动覆盖文件自动重载
+	if config.Overrides.Path != "" && config.Overrides.ReloadInterval > 0 {
+		watcher, err := ipquery.NewFileWatcher(config.Overrides.Path, config.Overrides.ReloadInterval, s.ReloadOverrides, logger)
+		if err != nil {
+			s.Close()
+			return nil, errors.NewWithError(errors.ErrCodeInternalError, "初始化覆盖文件监视器失败", err)
+		}
+		watcher.Start()
+		s.watchers = append(s.watchers, watcher)
+	}
+
+	return s, nil
+}
+
+// initEnrichers 创建已配置的增强器，并为设置了重载间隔的增强器启动文件监视
+func (s *IPService) initEnrichers() error {
+	if s.config.ASN.Path != "" {
+		enricher, err := ipquery.NewASNEnricher(s.config.ASN.Path)
+		if err != nil {
+			return errors.NewWithError(errors.ErrCodeDatabaseError, "加载ASN数据库失败", err)
+		}
+		if err := s.addEnricher(enricher, s.config.ASN.ReloadInterval); err != nil {
+			return err
+		}
+	}
+
+	if len(s.config.Reputation.Lists) > 0 {
+		lists := make([]ipquery.ReputationList, 0, len(s.config.Reputation.Lists))
+		for _, list := range s.config.Reputation.Lists {
+			lists = append(lists, ipquery.ReputationList{
+				Name:     list.Name,
+				Category: ipquery.ThreatCategory(list.Category),
+				Path:     list.Path,
+			})
+		}
+		enricher, err := ipquery.NewReputationEnricher(lists)
+		if err != nil {
+			return errors.NewWithError(errors.ErrCodeDatabaseError, "加载IP信誉列表失败", err)
+		}
+		if err := s.addEnricher(enricher, s.config.Reputation.RefreshInterval); err != nil {
+			return err
+		}
+	}
+
+	if s.config.Gazetteer.Enabled {
+		enricher, err := ipquery.NewGazetteerEnricher(s.config.Gazetteer.Path)
+		if err != nil {
+			return errors.NewWithError(errors.ErrCodeDatabaseError, "加载地名库失败", err)
+		}
+		if err := s.addEnricher(enricher, s.config.Gazetteer.ReloadInterval); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addEnricher 登记增强器，interval大于0时监视其数据文件并在变化时重新加载
+func (s *IPService) addEnricher(enricher ipquery.Enricher, interval time.Duration) error {
+	s.enrichers = append(s.enrichers, enricher)
+	if interval <= 0 {
+		return nil
+	}
+
+	reload := func() error {
+		return s.ReloadEnricher(enricher)
+	}
+	for _, path := range enricher.Paths() {
+		watcher, err := ipquery.NewFileWatcher(path, interval, reload, s.logger)
+		if err != nil {
+			return errors.NewWithError(errors.ErrCodeInternalError, "初始化数据文件监视器失败", err)
+		}
+		watcher.Start()
+		s.watchers = append(s.watchers, watcher)
+	}
+	return nil
+}
+
+// ReloadEnricher 重新加载增强器数据，加载失败时继续使用原数据
+func (s *IPService) ReloadEnricher(enricher ipquery.Enricher) error {
+	if err := enricher.Reload(); err != nil {
+		return errors.NewWithError(errors.ErrCodeDatabaseError, "重新加载增强数据失败", err)
+	}
+	s.reloads.Add(1)
+
+	// 清空缓存，避免返回旧数据的结果
+	s.clearCache()
+
+	s.logger.WithField("enricher", enricher.Name()).Info("增强数据重新加载成功")
+	return nil
+}
+
+// clearCache 清空缓存，缓存后端不可用时仅记录警告，不影响已完成的重新加载
+func (s *IPService) clearCache() {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.Clear(); err != nil {
+		s.logger.WithError(err).Warn("清空缓存失败，缓存中可能残留旧结果")
+	}
+}
+
+// newProvider 根据ip_database.type创建IP查询提供者，启用校验时只返回通过校验的提供者
+func newProvider(config *config.Config) (ipquery.QueryProvider, error) {
+	return ipquery.NewValidatedProvider(DatabaseOptions(config.IPDatabase))
+}
+
+// ReloadDatabase 重新加载IP数据库
+// 新数据库在后台加载完成后才替换旧数据库，加载失败时继续使用旧数据库
+func (s *IPService) ReloadDatabase() error {
+	provider, err := newProvider(s.config)
+	if err != nil {
+		return errors.NewWithError(errors.ErrCodeDatabaseError, "重新加载IP数据库失败", err)
+	}
+
+	database := ipquery.DescribeDatabase(DatabaseOptions(s.config.IPDatabase), provider)
+	if err := s.provider.Swap(provider); err != nil {
+		provider.Close()
+		return errors.NewWithError(errors.ErrCodeDatabaseError, "替换IP数据库失败", err)
+	}
+	s.database.Store(database)
+
+	// 清空缓存，避免返回旧数据库的结果
+	s.clearCache()
+
+	s.logger.WithField("path", s.config.IPDatabase.Path).
+		WithField("sha256", strings.Join(database.Checksums(), ",")).
+		Info("IP数据库重新加载成功")
+	return nil
+}
+
+// ReloadOverrides 重新加载CIDR覆盖表，加载失败时继续使用原覆盖表
+func (s *IPService) ReloadOverrides() error {
+	if err := s.overrides.Reload(); err != nil {
+		return errors.NewWithError(errors.ErrCodeInternalError, "重新加载CIDR覆盖表失败", err)
+	}
+	s.reloads.Add(1)
+
+	// 清空缓存，避免返回旧覆盖表的结果
+	s.clearCache()
+
+	s.logger.WithField("path", s.overrides.Path()).WithField("count", s.overrides.Len()).Info("CIDR覆盖表重新加载成功")
+	return nil
 }
 
 // QueryIP 查询单个IP地址信息
@@ -59,22 +288,201 @@ func (s *IPService) QueryIP(ip string) (*ipquery.IPInfo, error) {
 		}
 	}
 
+	return s.load(ip)
+}
+
+// generation 返回当前数据版本，替换数据库或重新加载覆盖表、增强数据后递增
+func (s *IPService) generation() uint64 {
+	return s.provider.Generation() + s.reloads.Load()
+}
+
+// load 查询未命中缓存的IP，同一IP的并发查询合并为一次数据源查询并共享结果
+// 合并以数据版本区分，重新加载后的查询不会共享旧数据源的结果
+func (s *IPService) load(ip string) (*ipquery.IPInfo, error) {
+	gen := s.generation()
+	executed := false
+	v, err, _ := s.flight.Do(strconv.FormatUint(gen, 10)+"|"+ip, func() (interface{}, error) {
+		executed = true
+		return s.lookup(ip, gen)
+	})
+	if !executed {
+		atomic.AddInt64(&s.collapsedCount, 1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v.(*ipquery.IPInfo), nil
+}
+
+// lookup 查询数据源、补充增强信息并写入缓存，gen为查询开始时的数据版本
+func (s *IPService) lookup(ip string, gen uint64) (*ipquery.IPInfo, error) {
 	// 查询IP信息
-	info, err := s.provider.Query(ip)
+	info, err := s.overrides.Query(ip)
 	if err != nil {
 		s.logger.WithError(err).WithField("ip", ip).Error("查询IP信息失败")
 		return nil, errors.NewWithError(errors.ErrCodeInternalError, "查询IP信息失败", err)
 	}
 
-	// 缓存结果
-	if s.cache != nil && info.IsValid {
+	// 补充增强信息并标注地址类型，所有数据源的结果统一处理
+	if info.IsValid {
+		for _, enricher := range s.enrichers {
+			enricher.Enrich(info)
+		}
+	}
+	info.Classify()
+
+	// 缓存结果，查询期间数据版本变化时结果可能来自旧数据，不写入缓存
+	if s.cache != nil && info.IsValid && s.generation() == gen {
 		s.cache.Set(ip, info)
+		// 写入前的检查与重新加载后的清空缓存之间存在间隙，写入后版本变化则删除刚写入的结果
+		if s.generation() != gen {
+			if err := s.cache.Delete(ip); err != nil {
+				s.logger.WithError(err).WithField("ip", ip).Warn("删除旧数据的缓存结果失败")
+			}
+		}
 	}
 
 	s.logger.WithField("ip", ip).WithField("country", info.Country).Info("查询IP信息成功")
 	return info, nil
 }
 
+// 网段查询限制
+const (
+	// MaxCIDRRanges 网段查询返回的子范围上限
+	MaxCIDRRanges = 1000
+	// minCIDRBitsV4 IPv4网段的最短前缀
+	minCIDRBitsV4 = 8
+	// minCIDRBitsV6 IPv6网段的最短前缀
+	minCIDRBitsV6 = 16
+)
+
+// QueryCIDR 查询网段内的所有子范围及其国家、ISP汇总
+// limit为返回的子范围数量，<=0或超过MaxCIDRRanges时使用MaxCIDRRanges
+func (s *IPService) QueryCIDR(cidr string, limit int) (*ipquery.CIDRReport, error) {
+	prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeInvalidRequest, "无效的网段格式")
+	}
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+
+	minBits := minCIDRBitsV6
+	if prefix.Addr().Is4() {
+		minBits = minCIDRBitsV4
+	}
+	if prefix.Bits() < minBits {
+		return nil, errors.New(errors.ErrCodeInvalidRequest, "网段过大")
+	}
+
+	if limit <= 0 || limit > MaxCIDRRanges {
+		limit = MaxCIDRRanges
+	}
+
+	report, err := ipquery.SummarizePrefix(s.overrides, prefix, limit)
+	if err != nil {
+		s.logger.WithError(err).WithField("prefix", cidr).Error("遍历IP段失败")
+		return nil, walkError(err)
+	}
+
+	s.logger.WithField("prefix", report.Prefix).WithField("ranges", report.TotalRanges).Info("查询网段信息成功")
+	return report, nil
+}
+
+// IP段反查分页限制
+const (
+	// DefaultRangePageSize 反查默认每页IP段数量
+	DefaultRangePageSize = 100
+	// MaxRangePageSize 反查每页IP段数量上限
+	MaxRangePageSize = 1000
+)
+
+// FindRanges 按国家、省份、城市或ISP反查IP段，返回分页结果
+// after为上一页返回的游标，为空时从头开始；limit<=0时使用DefaultRangePageSize，
+// 超过MaxRangePageSize时使用MaxRangePageSize；withTotal为true时统计全部匹配数量
+func (s *IPService) FindRanges(filter ipquery.RangeFilter, after string, offset, limit int, withTotal bool) (*ipquery.RangePage, error) {
+	if filter.IsEmpty() {
+		return nil, errors.New(errors.ErrCodeInvalidRequest, "至少需要指定一个过滤条件")
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = DefaultRangePageSize
+	}
+	if limit > MaxRangePageSize {
+		limit = MaxRangePageSize
+	}
+
+	query := ipquery.RangePageQuery{Offset: offset, Limit: limit, Total: withTotal}
+	if after = strings.TrimSpace(after); after != "" {
+		addr, err := netip.ParseAddr(after)
+		if err != nil {
+			return nil, errors.New(errors.ErrCodeInvalidRequest, "无效的分页游标")
+		}
+		query.After = addr
+	}
+
+	page, err := ipquery.FindRangePage(s.overrides, filter, query)
+	if err != nil {
+		s.logger.WithError(err).Error("遍历IP段失败")
+		return nil, walkError(err)
+	}
+
+	s.logger.WithField("count", len(page.Ranges)).WithField("next", page.Next).Info("反查IP段成功")
+	return page, nil
+}
+
+// StreamRanges 按地址顺序对满足条件的所有IP段调用fn，用于流式输出全部结果
+// fn返回错误时停止遍历并原样返回该错误
+func (s *IPService) StreamRanges(filter ipquery.RangeFilter, fn ipquery.RangeFunc) error {
+	if filter.IsEmpty() {
+		return errors.New(errors.ErrCodeInvalidRequest, "至少需要指定一个过滤条件")
+	}
+
+	var fnErr error
+	err := ipquery.FindRanges(s.overrides, filter, func(start, end netip.Addr, info *ipquery.IPInfo) error {
+		fnErr = fn(start, end, info)
+		return fnErr
+	})
+	if err != nil {
+		if fnErr != nil {
+			return fnErr
+		}
+		s.logger.WithError(err).Error("遍历IP段失败")
+		return walkError(err)
+	}
+	return nil
+}
+
+// ExportRanges 反查满足条件的IP段，以ipset、nftables等格式写入w
+func (s *IPService) ExportRanges(filter ipquery.RangeFilter, format string, opts ipquery.ExportOptions, w io.Writer) error {
+	if filter.IsEmpty() {
+		return errors.New(errors.ErrCodeInvalidRequest, "至少需要指定一个过滤条件")
+	}
+
+	exporter, err := ipquery.NewExporter(format, w, opts)
+	if err != nil {
+		return errors.NewWithError(errors.ErrCodeInvalidRequest, "无效的导出参数", err)
+	}
+
+	if err := ipquery.ExportRanges(s.overrides, filter, exporter); err != nil {
+		s.logger.WithError(err).WithField("format", format).Error("导出IP段失败")
+		return walkError(err)
+	}
+
+	s.logger.WithField("format", format).Info("导出IP段成功")
+	return nil
+}
+
+// walkError 转换IP段遍历错误
+func walkError(err error) error {
+	if stderrors.Is(err, ipquery.ErrRangeWalkNotSupported) {
+		return errors.New(errors.ErrCodeInvalidRequest, "当前数据源不支持IP段遍历")
+	}
+	return errors.NewWithError(errors.ErrCodeDatabaseError, "遍历IP段失败", err)
+}
+
 // BatchQueryIP 批量查询IP地址信息
 func (s *IPService) BatchQueryIP(ips []string) ([]*ipquery.IPInfo, error) {
 	if len(ips) == 0 {
@@ -87,10 +495,33 @@ func (s *IPService) BatchQueryIP(ips []string) ([]*ipquery.IPInfo, error) {
 
 	atomic.AddInt64(&s.queryCount, int64(len(ips)))
 
+	// 一次批量读取所有有效IP的缓存，共享缓存只需一次网络往返
+	var cached map[string]*ipquery.IPInfo
+	if s.cache != nil {
+		valid := make([]string, 0, len(ips))
+		for _, ip := range ips {
+			if ipquery.ValidateIP(ip) {
+				valid = append(valid, ip)
+			}
+		}
+		cached = s.cache.GetMulti(valid)
+	}
+
 	results := make([]*ipquery.IPInfo, 0, len(ips))
 
 	for _, ip := range ips {
-		info, err := s.QueryIP(ip)
+		if info, found := cached[ip]; found {
+			results = append(results, info)
+			continue
+		}
+
+		var info *ipquery.IPInfo
+		var err error
+		if ipquery.ValidateIP(ip) {
+			info, err = s.load(ip)
+		} else {
+			err = errors.New(errors.ErrCodeInvalidRequest, "无效的IP地址格式")
+		}
 		if err != nil {
 			info = &ipquery.IPInfo{
 				IP:           ip,
@@ -105,29 +536,71 @@ func (s *IPService) BatchQueryIP(ips []string) ([]*ipquery.IPInfo, error) {
 	return results, nil
 }
 
+// SetBuildInfo 设置服务的构建信息，为空的字段保持默认值
+func (s *IPService) SetBuildInfo(info BuildInfo) {
+	if info.Version != "" {
+		s.build.Version = info.Version
+	}
+	if info.BuildTime != "" {
+		s.build.BuildTime = info.BuildTime
+	}
+	if info.GitCommit != "" {
+		s.build.GitCommit = info.GitCommit
+	}
+}
+
+// DatabaseInfo 返回当前使用的IP数据库的来源信息
+func (s *IPService) DatabaseInfo() *ipquery.DatabaseInfo {
+	return s.database.Load()
+}
+
 // GetServiceStatus 获取服务状态
 func (s *IPService) GetServiceStatus() map[string]interface{} {
+	cache := s.getCacheStats()
+	cacheSize := 0
+	if cache != nil {
+		cacheSize = cache.Size
+	}
+
 	return map[string]interface{}{
-		"status":      "running",
-		"version":     "1.0.0",
-		"uptime":      time.Since(s.startTime).Seconds(),
-		"query_count": atomic.LoadInt64(&s.queryCount),
-		"cache_size":  s.getCacheSize(),
+		"status":          "running",
+		"version":         s.build.Version,
+		"build_time":      s.build.BuildTime,
+		"git_commit":      s.build.GitCommit,
+		"uptime":          time.Since(s.startTime).Seconds(),
+		"query_count":     atomic.LoadInt64(&s.queryCount),
+		"collapsed_count": atomic.LoadInt64(&s.collapsedCount),
+		"cache_size":      cacheSize,
+		"cache":           cache,
+		"database":        s.DatabaseInfo(),
 	}
 }
 
-// getCacheSize 获取缓存大小
-func (s *IPService) getCacheSize() int {
-	if s.cache != nil {
-		return s.cache.Size()
+// getCacheStats 获取缓存统计，未启用缓存时返回nil
+func (s *IPService) getCacheStats() *ipquery.CacheStats {
+	if s.cache == nil {
+		return nil
 	}
-	return 0
+	stats := s.cache.Stats()
+	return &stats
 }
 
 // Close 关闭服务
 func (s *IPService) Close() error {
-	if s.provider != nil {
-		return s.provider.Close()
+	if s.fetcher != nil {
+		s.fetcher.Stop()
+	}
+	for _, watcher := range s.watchers {
+		watcher.Stop()
+	}
+	for _, enricher := range s.enrichers {
+		enricher.Close()
+	}
+	if s.cache != nil {
+		s.cache.Close()
+	}
+	if s.overrides != nil {
+		return s.overrides.Close()
 	}
 	return nil
 }
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"github.com/ushell/goip/internal/config"
+	"github.com/ushell/goip/internal/ipquery"
+)
+
+// DatabaseOptions 将ip_database配置转换为ipquery的提供者选项
+func DatabaseOptions(cfg config.IPDatabaseConfig) ipquery.DatabaseOptions {
+	opts := ipquery.DatabaseOptions{
+		Type:      cfg.Type,
+		Path:      cfg.Path,
+		IPv6Path:  cfg.IPv6Path,
+		LoadMode:  ipquery.LoadMode(cfg.LoadMode),
+		PoolSize:  cfg.PoolSize,
+		CacheSize: cfg.CacheSize,
+		MMDB: ipquery.MMDBOptions{
+			CityPath:    cfg.MMDB.CityPath,
+			CountryPath: cfg.MMDB.CountryPath,
+			ASNPath:     cfg.MMDB.ASNPath,
+			Language:    cfg.MMDB.Language,
+		},
+		Composite: ipquery.CompositeOptions{
+			Precedence: cfg.Composite.Precedence,
+		},
+		Remote: ipquery.RemoteOptions{
+			URL:          cfg.Remote.URL,
+			Format:       cfg.Remote.Format,
+			Timeout:      cfg.Remote.Timeout,
+			SHA256:       cfg.Remote.SHA256,
+			ChecksumURL:  cfg.Remote.ChecksumURL,
+			PublicKey:    cfg.Remote.PublicKey,
+			SignatureURL: cfg.Remote.SignatureURL,
+			Insecure:     cfg.Remote.Insecure,
+		},
+		Validate: cfg.Validation.Enabled,
+		Validation: ipquery.ValidationOptions{
+			Completeness: cfg.Validation.Completeness,
+			Samples:      cfg.Validation.Samples,
+		},
+	}
+	for _, member := range cfg.Composite.Providers {
+		opts.Composite.Members = append(opts.Composite.Members, ipquery.CompositeMemberOptions{
+			Type:    member.Type,
+			Timeout: member.Timeout,
+			Fields:  member.Fields,
+		})
+	}
+	for _, c := range cfg.Validation.Canaries {
+		opts.Validation.Canaries = append(opts.Validation.Canaries, ipquery.Canary{
+			IP:          c.IP,
+			Country:     c.Country,
+			CountryCode: c.CountryCode,
+			Region:      c.Region,
+			City:        c.City,
+			ISP:         c.ISP,
+		})
+	}
+	return opts
+}
+
+// CacheOptions 将cache配置转换为ipquery的缓存选项
+func CacheOptions(cfg config.CacheConfig) ipquery.CacheOptions {
+	return ipquery.CacheOptions{
+		Type:     cfg.Type,
+		TTL:      cfg.TTL,
+		LocalTTL: cfg.LocalTTL,
+		MaxSize:  cfg.MaxSize,
+		MaxBytes: cfg.MaxBytes,
+		Policy:   ipquery.CachePolicy(cfg.Policy),
+		Redis: ipquery.RedisOptions{
+			Addr:          cfg.Redis.Addr,
+			Username:      cfg.Redis.Username,
+			Password:      cfg.Redis.Password,
+			DB:            cfg.Redis.DB,
+			PoolSize:      cfg.Redis.PoolSize,
+			Prefix:        cfg.Redis.Prefix,
+			Timeout:       cfg.Redis.Timeout,
+			RetryInterval: cfg.Redis.RetryInterval,
+		},
+	}
+}
@@ -3,6 +3,8 @@ package errors
 import (
 	"errors"
 	"fmt"
+
+	"github.com/ushell/goip/pkg/i18n"
 )
 
 // ErrorCode 错误码类型
@@ -15,6 +17,9 @@ const (
 	ErrCodeCacheError
 	ErrCodeInternalError
 	ErrCodeInvalidRequest
+	ErrCodeUnauthorized
+	ErrCodeForbidden
+	ErrCodeCacheUnavailable
 )
 
 // AppError 应用错误
@@ -81,6 +86,11 @@ func GetMessage(err error) string {
 	return err.Error()
 }
 
+// GetLocalizedMessage 获取指定语言的错误消息
+func GetLocalizedMessage(err error, lang i18n.Lang) string {
+	return i18n.Message(GetMessage(err), lang)
+}
+
 // 预定义错误
 var (
 	ErrInvalidIP      = New(ErrCodeInvalidIP, "无效的IP地址")
@@ -0,0 +1,235 @@
+package i18n
+
+// messages 服务消息的英文翻译，键为中文原文
+var messages = map[string]string{
+	"无效的IP地址":          "Invalid IP address",
+	"无效的IP地址格式":        "Invalid IP address format",
+	"IP地址不能为空":         "IP address must not be empty",
+	"IP列表不能为空":         "IP list must not be empty",
+	"单次查询IP数量不能超过100个": "At most 100 IPs can be queried at once",
+	"请求格式错误":           "Malformed request",
+	"无效的请求":            "Invalid request",
+	"数据库错误":            "Database error",
+	"缓存错误":             "Cache error",
+	"内部错误":             "Internal error",
+	"查询IP信息失败":         "Failed to query IP information",
+	"初始化IP查询提供者失败":     "Failed to initialize IP query provider",
+	"初始化数据库监视器失败":      "Failed to initialize database watcher",
+	"重新加载IP数据库失败":      "Failed to reload IP database",
+	"替换IP数据库失败":        "Failed to swap IP database",
+	"IP查询失败":           "IP lookup failed",
+	"数据库中未找到该IP":       "IP address not found in database",
+	"所有数据源均超时":         "all data sources timed out",
+	"加载CIDR覆盖表失败":      "Failed to load CIDR overrides",
+	"重新加载CIDR覆盖表失败":    "Failed to reload CIDR overrides",
+	"初始化覆盖文件监视器失败":     "Failed to initialize override file watcher",
+	"加载ASN数据库失败":       "Failed to load ASN database",
+	"加载地名库失败":          "Failed to load gazetteer",
+	"加载IP信誉列表失败":       "Failed to load IP reputation lists",
+	"无效的网段格式":          "Invalid CIDR prefix",
+	"无效的分页游标":          "Invalid page cursor",
+	"网段过大":             "Prefix is too large",
+	"当前数据源不支持IP段遍历":    "The configured data source does not support range walking",
+	"遍历IP段失败":          "Failed to walk IP ranges",
+	"至少需要指定一个过滤条件":     "At least one filter is required",
+	"不支持的输出格式":         "Unsupported output format",
+	"无效的导出参数":          "Invalid export parameters",
+	"初始化远程数据库下载失败":     "Failed to initialize remote database download",
+	"初始化缓存失败":          "Failed to initialize cache",
+	"缓存未启用":            "Cache is not enabled",
+	"缓存后端不可用":          "Cache backend is unavailable",
+	"管理接口未授权":          "Unauthorized admin request",
+	"管理接口未启用":          "Admin API is disabled",
+	"初始化数据文件监视器失败":     "Failed to initialize data file watcher",
+	"重新加载增强数据失败":       "Failed to reload enrichment data",
+}
+
+// places 通用地点标记
+var places = map[string]string{
+	"局域网":  "LAN",
+	"内网IP": "Intranet",
+	"本机地址": "Localhost",
+	"保留地址": "Reserved",
+	"未知":   "Unknown",
+}
+
+// regionSuffixes 省级行政区名称后缀，按长度从长到短排列
+var regionSuffixes = []string{
+	"维吾尔自治区",
+	"壮族自治区",
+	"回族自治区",
+	"特别行政区",
+	"自治区",
+	"省",
+	"市",
+}
+
+// regions 省级行政区及常见境外州/省的英文名称，键为去除后缀的中文名
+var regions = map[string]string{
+	"北京":      "Beijing",
+	"天津":      "Tianjin",
+	"上海":      "Shanghai",
+	"重庆":      "Chongqing",
+	"河北":      "Hebei",
+	"山西":      "Shanxi",
+	"辽宁":      "Liaoning",
+	"吉林":      "Jilin",
+	"黑龙江":     "Heilongjiang",
+	"江苏":      "Jiangsu",
+	"浙江":      "Zhejiang",
+	"安徽":      "Anhui",
+	"福建":      "Fujian",
+	"江西":      "Jiangxi",
+	"山东":      "Shandong",
+	"河南":      "Henan",
+	"湖北":      "Hubei",
+	"湖南":      "Hunan",
+	"广东":      "Guangdong",
+	"海南":      "Hainan",
+	"四川":      "Sichuan",
+	"贵州":      "Guizhou",
+	"云南":      "Yunnan",
+	"陕西":      "Shaanxi",
+	"甘肃":      "Gansu",
+	"青海":      "Qinghai",
+	"台湾":      "Taiwan",
+	"内蒙古":     "Inner Mongolia",
+	"广西":      "Guangxi",
+	"西藏":      "Tibet",
+	"宁夏":      "Ningxia",
+	"新疆":      "Xinjiang",
+	"香港":      "Hong Kong",
+	"澳门":      "Macao",
+	"加利福尼亚":   "California",
+	"加利福尼亚州":  "California",
+	"纽约":      "New York",
+	"纽约州":     "New York",
+	"德克萨斯":    "Texas",
+	"德克萨斯州":   "Texas",
+	"华盛顿":     "Washington",
+	"华盛顿州":    "Washington",
+	"弗吉尼亚":    "Virginia",
+	"弗吉尼亚州":   "Virginia",
+	"伊利诺伊":    "Illinois",
+	"伊利诺伊州":   "Illinois",
+	"佛罗里达":    "Florida",
+	"佛罗里达州":   "Florida",
+	"俄勒冈":     "Oregon",
+	"俄勒冈州":    "Oregon",
+	"新泽西":     "New Jersey",
+	"新泽西州":    "New Jersey",
+	"东京都":     "Tokyo",
+	"东京":      "Tokyo",
+	"大阪府":     "Osaka",
+	"大阪":      "Osaka",
+	"首尔":      "Seoul",
+	"京畿道":     "Gyeonggi-do",
+	"安大略":     "Ontario",
+	"安大略省":    "Ontario",
+	"不列颠哥伦比亚": "British Columbia",
+	"魁北克":     "Quebec",
+	"新南威尔士":   "New South Wales",
+	"维多利亚":    "Victoria",
+	"英格兰":     "England",
+	"苏格兰":     "Scotland",
+	"黑森":      "Hesse",
+	"巴伐利亚":    "Bavaria",
+	"法兰西岛":    "Île-de-France",
+	"莫斯科":     "Moscow",
+}
+
+// cities 常见城市的英文名称，键为去除"市"后缀的中文名
+var cities = map[string]string{
+	"石家庄":   "Shijiazhuang",
+	"太原":    "Taiyuan",
+	"呼和浩特":  "Hohhot",
+	"沈阳":    "Shenyang",
+	"大连":    "Dalian",
+	"长春":    "Changchun",
+	"哈尔滨":   "Harbin",
+	"南京":    "Nanjing",
+	"苏州":    "Suzhou",
+	"无锡":    "Wuxi",
+	"常州":    "Changzhou",
+	"杭州":    "Hangzhou",
+	"宁波":    "Ningbo",
+	"温州":    "Wenzhou",
+	"合肥":    "Hefei",
+	"福州":    "Fuzhou",
+	"厦门":    "Xiamen",
+	"泉州":    "Quanzhou",
+	"南昌":    "Nanchang",
+	"济南":    "Jinan",
+	"青岛":    "Qingdao",
+	"烟台":    "Yantai",
+	"郑州":    "Zhengzhou",
+	"洛阳":    "Luoyang",
+	"武汉":    "Wuhan",
+	"长沙":    "Changsha",
+	"广州":    "Guangzhou",
+	"深圳":    "Shenzhen",
+	"东莞":    "Dongguan",
+	"佛山":    "Foshan",
+	"珠海":    "Zhuhai",
+	"南宁":    "Nanning",
+	"桂林":    "Guilin",
+	"海口":    "Haikou",
+	"三亚":    "Sanya",
+	"成都":    "Chengdu",
+	"贵阳":    "Guiyang",
+	"昆明":    "Kunming",
+	"拉萨":    "Lhasa",
+	"西安":    "Xi'an",
+	"兰州":    "Lanzhou",
+	"西宁":    "Xining",
+	"银川":    "Yinchuan",
+	"乌鲁木齐":  "Urumqi",
+	"台北":    "Taipei",
+	"高雄":    "Kaohsiung",
+	"旧金山":   "San Francisco",
+	"洛杉矶":   "Los Angeles",
+	"圣何塞":   "San Jose",
+	"西雅图":   "Seattle",
+	"芝加哥":   "Chicago",
+	"达拉斯":   "Dallas",
+	"阿什本":   "Ashburn",
+	"山景城":   "Mountain View",
+	"伦敦":    "London",
+	"巴黎":    "Paris",
+	"柏林":    "Berlin",
+	"法兰克福":  "Frankfurt",
+	"阿姆斯特丹": "Amsterdam",
+	"新加坡":   "Singapore",
+	"悉尼":    "Sydney",
+	"墨尔本":   "Melbourne",
+	"多伦多":   "Toronto",
+	"温哥华":   "Vancouver",
+}
+
+// isps 常见运营商的英文名称
+var isps = map[string]string{
+	"电信":   "China Telecom",
+	"中国电信": "China Telecom",
+	"联通":   "China Unicom",
+	"中国联通": "China Unicom",
+	"移动":   "China Mobile",
+	"中国移动": "China Mobile",
+	"铁通":   "China Tietong",
+	"中国铁通": "China Tietong",
+	"广电":   "China Broadnet",
+	"中国广电": "China Broadnet",
+	"教育网":  "CERNET",
+	"科技网":  "CSTNET",
+	"鹏博士":  "Dr. Peng",
+	"长城宽带": "Great Wall Broadband",
+	"阿里云":  "Alibaba Cloud",
+	"阿里巴巴": "Alibaba",
+	"腾讯":   "Tencent",
+	"腾讯云":  "Tencent Cloud",
+	"华为云":  "Huawei Cloud",
+	"百度":   "Baidu",
+	"天翼云":  "China Telecom Cloud",
+	"谷歌":   "Google",
+	"微软":   "Microsoft",
+	"亚马逊":  "Amazon",
+}
@@ -0,0 +1,197 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ushell/goip/pkg/iso3166"
+)
+
+// Lang 语言标识
+type Lang string
+
+// 支持的语言
+const (
+	LangZhCN Lang = "zh-CN"
+	LangEn   Lang = "en"
+
+	// DefaultLang 默认语言，与数据源保持一致
+	DefaultLang = LangZhCN
+)
+
+// Parse 解析语言标识，不支持的语言返回false
+// zh、zh-CN、zh-Hans等视为简体中文，en、en-US等视为英文
+func Parse(tag string) (Lang, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return "", false
+	}
+
+	primary := tag
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		primary = tag[:i]
+	}
+
+	switch primary {
+	case "zh":
+		return LangZhCN, true
+	case "en":
+		return LangEn, true
+	default:
+		return "", false
+	}
+}
+
+// ParseAcceptLanguage 按HTTP Accept-Language头的权重选择支持的语言
+func ParseAcceptLanguage(header string) (Lang, bool) {
+	type candidate struct {
+		lang Lang
+		q    float64
+		pos  int
+	}
+
+	var candidates []candidate
+	for i, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		lang, ok := Parse(fields[0])
+		if !ok {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{lang: lang, q: q, pos: i})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	return candidates[0].lang, true
+}
+
+// Resolve 按显式参数、Accept-Language的顺序确定语言，均无效时返回默认语言
+func Resolve(param, acceptLanguage string) Lang {
+	if lang, ok := Parse(param); ok {
+		return lang
+	}
+	if lang, ok := ParseAcceptLanguage(acceptLanguage); ok {
+		return lang
+	}
+	return DefaultLang
+}
+
+// Message 翻译服务消息，支持"前缀: 详情"形式的消息，未收录的消息保持原文
+func Message(msg string, lang Lang) string {
+	if lang != LangEn || msg == "" {
+		return msg
+	}
+
+	if en, ok := messages[msg]; ok {
+		return en
+	}
+
+	// 带有详情的消息分别翻译前缀和详情，详情未收录时保持原文
+	for _, sep := range []string{": ", "："} {
+		if i := strings.Index(msg, sep); i > 0 {
+			if en, ok := messages[msg[:i]]; ok {
+				detail := msg[i+len(sep):]
+				if enDetail, ok := messages[detail]; ok {
+					detail = enDetail
+				}
+				return en + ": " + detail
+			}
+		}
+	}
+
+	return msg
+}
+
+// Country 翻译国家名称，优先使用ISO代码匹配
+func Country(name, code string, lang Lang) string {
+	if lang != LangEn || name == "" {
+		return name
+	}
+
+	if c, ok := iso3166.ByAlpha2(code); ok {
+		return c.NameEn
+	}
+	if c, ok := iso3166.ByChineseName(name); ok {
+		return c.NameEn
+	}
+	return Place(name, lang)
+}
+
+// Region 翻译省份/州名称
+func Region(name string, lang Lang) string {
+	if lang != LangEn || name == "" {
+		return name
+	}
+
+	if en, ok := regions[trimRegionSuffix(name)]; ok {
+		return en
+	}
+	return Place(name, lang)
+}
+
+// City 翻译城市名称
+func City(name string, lang Lang) string {
+	if lang != LangEn || name == "" {
+		return name
+	}
+
+	if en, ok := cities[strings.TrimSuffix(name, "市")]; ok {
+		return en
+	}
+	if en, ok := regions[trimRegionSuffix(name)]; ok {
+		// 直辖市等城市名与省级名称相同
+		return en
+	}
+	return Place(name, lang)
+}
+
+// ISP 翻译运营商名称
+func ISP(name string, lang Lang) string {
+	if lang != LangEn || name == "" {
+		return name
+	}
+
+	if en, ok := isps[name]; ok {
+		return en
+	}
+	return Place(name, lang)
+}
+
+// Place 翻译通用地点标记（如局域网、内网IP），未收录的名称保持原文
+func Place(name string, lang Lang) string {
+	if lang != LangEn {
+		return name
+	}
+	if en, ok := places[name]; ok {
+		return en
+	}
+	return name
+}
+
+// trimRegionSuffix 去除省级行政区名称后缀
+func trimRegionSuffix(name string) string {
+	for _, suffix := range regionSuffixes {
+		if strings.HasSuffix(name, suffix) && len(name) > len(suffix) {
+			return strings.TrimSuffix(name, suffix)
+		}
+	}
+	return name
+}
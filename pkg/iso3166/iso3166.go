@@ -0,0 +1,108 @@
+package iso3166
+
+import (
+	"strings"
+)
+
+// Country ISO 3166-1国家/地区信息
+type Country struct {
+	Alpha2  string `json:"alpha2"`  // 两位字母代码，如CN
+	Alpha3  string `json:"alpha3"`  // 三位字母代码，如CHN
+	Numeric string `json:"numeric"` // 三位数字代码，如156
+	NameZh  string `json:"name_zh"` // 中文名称
+	NameEn  string `json:"name_en"` // 英文名称
+}
+
+var (
+	byAlpha2  = make(map[string]*Country, len(countries))
+	byAlpha3  = make(map[string]*Country, len(countries))
+	byNumeric = make(map[string]*Country, len(countries))
+	byNameZh  = make(map[string]*Country, len(countries)+len(chineseAliases))
+	byNameEn  = make(map[string]*Country, len(countries)+len(englishAliases))
+)
+
+func init() {
+	for i := range countries {
+		c := &countries[i]
+		byAlpha2[c.Alpha2] = c
+		byAlpha3[c.Alpha3] = c
+		byNumeric[c.Numeric] = c
+		byNameZh[c.NameZh] = c
+		byNameEn[strings.ToLower(c.NameEn)] = c
+	}
+
+	for name, alpha2 := range chineseAliases {
+		c, ok := byAlpha2[alpha2]
+		if !ok {
+			panic("iso3166: unknown alias target " + alpha2)
+		}
+		byNameZh[name] = c
+	}
+
+	for name, alpha2 := range englishAliases {
+		c, ok := byAlpha2[alpha2]
+		if !ok {
+			panic("iso3166: unknown alias target " + alpha2)
+		}
+		byNameEn[strings.ToLower(name)] = c
+	}
+}
+
+// ByAlpha2 根据两位字母代码查找，不区分大小写
+func ByAlpha2(code string) (Country, bool) {
+	return lookup(byAlpha2, strings.ToUpper(strings.TrimSpace(code)))
+}
+
+// ByAlpha3 根据三位字母代码查找，不区分大小写
+func ByAlpha3(code string) (Country, bool) {
+	return lookup(byAlpha3, strings.ToUpper(strings.TrimSpace(code)))
+}
+
+// ByNumeric 根据数字代码查找，支持省略前导零
+func ByNumeric(code string) (Country, bool) {
+	code = strings.TrimSpace(code)
+	for len(code) < 3 {
+		code = "0" + code
+	}
+	return lookup(byNumeric, code)
+}
+
+// ByChineseName 根据中文名称查找，支持常用简称和别名
+func ByChineseName(name string) (Country, bool) {
+	return lookup(byNameZh, strings.TrimSpace(name))
+}
+
+// ByEnglishName 根据英文名称查找，不区分大小写，支持常用别名
+func ByEnglishName(name string) (Country, bool) {
+	return lookup(byNameEn, strings.ToLower(strings.TrimSpace(name)))
+}
+
+// ByName 依次按中文名称、英文名称、两位和三位字母代码查找
+func ByName(name string) (Country, bool) {
+	if c, ok := ByChineseName(name); ok {
+		return c, true
+	}
+	if c, ok := ByEnglishName(name); ok {
+		return c, true
+	}
+	if c, ok := ByAlpha2(name); ok {
+		return c, true
+	}
+	return ByAlpha3(name)
+}
+
+// All 返回全部国家/地区，按两位字母代码排序
+func All() []Country {
+	result := make([]Country, len(countries))
+	copy(result, countries)
+	return result
+}
+
+// lookup 查找并返回副本
+func lookup(index map[string]*Country, key string) (Country, bool) {
+	c, ok := index[key]
+	if !ok {
+		return Country{}, false
+	}
+	return *c, true
+}